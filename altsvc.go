@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// altSvcCache remembers the alternative services advertised by origins through the Alt-Svc
+// header and resolves dial addresses to a usable alternative until the advertisement expires.
+type altSvcCache struct {
+	mutex   sync.RWMutex
+	entries map[string]altSvcEntry
+}
+
+// altSvcEntry is a remembered alternative for a single origin.
+type altSvcEntry struct {
+	authority string
+	expires   time.Time
+}
+
+// altSvcProtocols lists the ALPN protocols the client can speak to an alternative.
+var altSvcProtocols = map[string]struct{}{
+	"h2":       {},
+	"http/1.1": {},
+}
+
+// record stores the first usable alternative advertised by a response for the origin of the
+// request URL, or forgets the origin's alternatives if the response clears them.
+//
+// Parameters:
+//   - u: The URL of the request that produced the response.
+//   - header: The response header.
+//
+// Returns: None.
+func (c *altSvcCache) record(u *url.URL, header http.Header) {
+	// Alternatives are only trusted for origins whose identity is verified through TLS.
+	if u.Scheme != "https" {
+		return
+	}
+
+	value := header.Get(headers.AltSvc.String())
+
+	if value == "" {
+		return
+	}
+
+	services, cleared, err := headers.ParseAltSvc(value)
+	if err != nil {
+		return
+	}
+
+	origin := originAddress(u)
+
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	if cleared {
+		delete(c.entries, origin)
+
+		return
+	}
+
+	for _, service := range services {
+		if _, ok := altSvcProtocols[service.Protocol]; !ok {
+			continue
+		}
+
+		c.entries[origin] = altSvcEntry{
+			authority: service.Authority(u.Hostname()),
+			expires:   time.Now().Add(service.MaxAge),
+		}
+
+		return
+	}
+}
+
+// lookup returns the remembered alternative for the given dial address, if any is still fresh.
+//
+// Parameters:
+//   - addr: The host:port address about to be dialed.
+//
+// Returns:
+//   - authority: The host:port of the alternative to dial instead.
+//   - ok: Whether a fresh alternative exists.
+func (c *altSvcCache) lookup(addr string) (authority string, ok bool) {
+	c.mutex.RLock()
+
+	entry, exists := c.entries[addr]
+
+	c.mutex.RUnlock()
+
+	if !exists {
+		return
+	}
+
+	if time.Now().After(entry.expires) {
+		c.mutex.Lock()
+
+		delete(c.entries, addr)
+
+		c.mutex.Unlock()
+
+		return
+	}
+
+	authority, ok = entry.authority, true
+
+	return
+}
+
+// dialContext wraps a dial function so that connections to origins with a remembered
+// alternative are established to the alternative instead. TLS server name verification
+// is unaffected, as the transport keeps using the origin host for it.
+//
+// Parameters:
+//   - dial: The dial function to wrap.
+//
+// Returns:
+//   - wrapped: The wrapped dial function.
+func (c *altSvcCache) dialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) (wrapped func(ctx context.Context, network, addr string) (net.Conn, error)) {
+	wrapped = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if authority, ok := c.lookup(addr); ok {
+			addr = authority
+		}
+
+		return dial(ctx, network, addr)
+	}
+
+	return
+}
+
+// newAltSvcCache creates an empty altSvcCache.
+//
+// Parameters: None.
+//
+// Returns:
+//   - cache: The new cache.
+func newAltSvcCache() (cache *altSvcCache) {
+	cache = &altSvcCache{
+		entries: make(map[string]altSvcEntry),
+	}
+
+	return
+}
+
+// originAddress returns the host:port address of the origin of a URL, filling in the
+// scheme's default port when none is given.
+//
+// Parameters:
+//   - u: The URL.
+//
+// Returns:
+//   - addr: The origin's host:port address.
+func originAddress(u *url.URL) (addr string) {
+	port := u.Port()
+
+	if port == "" {
+		port = "80"
+
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	addr = net.JoinHostPort(u.Hostname(), port)
+
+	return
+}
@@ -0,0 +1,248 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"go.source.hueristiq.com/http/headers"
+	"go.source.hueristiq.com/http/mime"
+)
+
+// ErrMissingBatchResponse is set on the BatchResult of an inner request the batch response has
+// no response for.
+var ErrMissingBatchResponse = errors.New("no response in batch for request")
+
+// batchContentIDPrefix prefixes the index of an inner request in its Content-ID.
+const batchContentIDPrefix = "item-"
+
+// BatchResult is the outcome of an inner request of a batch.
+type BatchResult struct {
+	Request  *http.Request  // The inner request.
+	Response *http.Response // The inner response, its body buffered, nil if Err is set.
+	Err      error          // An error if the batch response has no response for the request.
+}
+
+// Batch packs inner requests into a single multipart/mixed batch request, as used by Google and
+// OData style batch APIs, sends it, and splits the batch response back into the response of
+// each inner request. Responses are matched to requests through their Content-ID, or by
+// position for responses without one.
+//
+// Parameters:
+//   - ctx: The context of the batch request.
+//   - URL: The URL of the batch endpoint, joined to the client's BaseURL.
+//   - requests: The inner requests. Their URLs are sent as request targets (path and query).
+//
+// Returns:
+//   - results: The results, in the order of the requests.
+//   - err: An error if the batch cannot be composed, sent, or parsed.
+func (c *Client) Batch(ctx context.Context, URL string, requests ...*http.Request) (results []BatchResult, err error) {
+	body, contentType, err := composeBatch(requests)
+	if err != nil {
+		return
+	}
+
+	res, err := c.POST(URL).
+		Context(ctx).
+		SetHeader(headers.ContentType.String(), contentType).
+		Body(body).
+		Send()
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	results, err = splitBatch(res, requests)
+
+	return
+}
+
+// composeBatch serializes inner requests as the application/http parts of a multipart/mixed body.
+//
+// Parameters:
+//   - requests: The inner requests.
+//
+// Returns:
+//   - body: The multipart body.
+//   - contentType: The Content-Type of the body, boundary included.
+//   - err: An error if the body of a request cannot be read.
+func composeBatch(requests []*http.Request) (body []byte, contentType string, err error) {
+	var buf bytes.Buffer
+
+	writer := multipart.NewWriter(&buf)
+
+	for index, req := range requests {
+		header := textproto.MIMEHeader{}
+
+		header.Set(headers.ContentType.String(), "application/http")
+		header.Set("Content-Transfer-Encoding", "binary")
+		header.Set("Content-ID", "<"+batchContentIDPrefix+strconv.Itoa(index)+">")
+
+		var part io.Writer
+
+		part, err = writer.CreatePart(header)
+		if err != nil {
+			return
+		}
+
+		if err = writeBatchRequest(part, req); err != nil {
+			return
+		}
+	}
+
+	if err = writer.Close(); err != nil {
+		return
+	}
+
+	body = buf.Bytes()
+	contentType = mime.MultipartMixed.String() + "; boundary=" + writer.Boundary()
+
+	return
+}
+
+// writeBatchRequest writes an inner request as an HTTP/1.1 message.
+//
+// Parameters:
+//   - w: The writer of the part.
+//   - req: The inner request.
+//
+// Returns:
+//   - err: An error if the body of the request cannot be read.
+func writeBatchRequest(w io.Writer, req *http.Request) (err error) {
+	var body []byte
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return
+		}
+
+		req.Body.Close()
+
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	header := req.Header.Clone()
+
+	if header == nil {
+		header = http.Header{}
+	}
+
+	if len(body) > 0 {
+		header.Set(headers.ContentLength.String(), strconv.Itoa(len(body)))
+	}
+
+	if _, err = fmt.Fprintf(w, "%s %s HTTP/1.1\r\n", req.Method, req.URL.RequestURI()); err != nil {
+		return
+	}
+
+	if err = header.Write(w); err != nil {
+		return
+	}
+
+	if _, err = io.WriteString(w, "\r\n"); err != nil {
+		return
+	}
+
+	_, err = w.Write(body)
+
+	return
+}
+
+// splitBatch reads the inner responses of a batch response.
+//
+// Parameters:
+//   - res: The batch response.
+//   - requests: The inner requests.
+//
+// Returns:
+//   - results: The results, in the order of the requests.
+//   - err: An error if the batch response is malformed.
+func splitBatch(res *http.Response, requests []*http.Request) (results []BatchResult, err error) {
+	reader, err := NewMultipartReader(res)
+	if err != nil {
+		return
+	}
+
+	results = make([]BatchResult, len(requests))
+
+	for index, req := range requests {
+		results[index] = BatchResult{Request: req, Err: ErrMissingBatchResponse}
+	}
+
+	for position := 0; ; position++ {
+		var part *ResponsePart
+
+		part, err = reader.Next()
+		if errors.Is(err, io.EOF) {
+			err = nil
+
+			return
+		}
+
+		if err != nil {
+			return
+		}
+
+		index := batchIndex(part.Header.Get("Content-ID"), position)
+
+		if index < 0 || index >= len(requests) {
+			continue
+		}
+
+		var inner *http.Response
+
+		inner, err = http.ReadResponse(bufio.NewReader(part.Body), requests[index])
+		if err != nil {
+			return
+		}
+
+		// Part bodies are only valid until the next part is read, so inner bodies are buffered.
+		var body []byte
+
+		body, err = io.ReadAll(inner.Body)
+		if err != nil {
+			return
+		}
+
+		inner.Body = io.NopCloser(bytes.NewReader(body))
+
+		results[index] = BatchResult{Request: requests[index], Response: inner}
+	}
+}
+
+// batchIndex returns the index of the inner request a response part answers, from its
+// Content-ID (e.g., "<response-item-1>"), or its position if it has none.
+//
+// Parameters:
+//   - contentID: The Content-ID of the part.
+//   - position: The position of the part in the batch response.
+//
+// Returns:
+//   - index: The index of the inner request.
+func batchIndex(contentID string, position int) (index int) {
+	index = position
+
+	contentID = strings.Trim(contentID, "<>")
+
+	at := strings.LastIndex(contentID, batchContentIDPrefix)
+
+	if at < 0 {
+		return
+	}
+
+	if parsed, err := strconv.Atoi(contentID[at+len(batchContentIDPrefix):]); err == nil {
+		index = parsed
+	}
+
+	return
+}
@@ -0,0 +1,256 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	hqgoreaderutil "github.com/hueristiq/hqgoutils/reader"
+)
+
+// Allocation budgets of the hot paths benchmarked below, enforced by TestAllocationBudgets so
+// that regressions fail the build instead of going unnoticed. Raise a budget only together
+// with the change that justifies it.
+const (
+	buildAllocBudget        = 36  // Building a request with default and per-request headers and parameters.
+	headerAllocBudget       = 16  // Creating a builder from the client's default headers and setting headers on it.
+	reusableBodyAllocBudget = 10  // Reading a reusable request body again, as done before each retry.
+	retryLoopAllocBudget    = 300 // Sending a request answered by three 503 responses, then a 200 one.
+)
+
+// newBenchmarkClient creates a client with default headers and parameters, sending through a
+// transport answering every request in memory.
+func newBenchmarkClient(tb testing.TB, retries int, transport http.RoundTripper) (client *Client) {
+	tb.Helper()
+
+	client, err := NewClient(&ClientConfiguration{
+		HTTPClient:   &http.Client{Transport: transport},
+		BaseURL:      "https://example.com/api",
+		Headers:      map[string]string{"User-Agent": "benchmark", "Accept": "application/json", "X-Client": "1"},
+		Params:       map[string]string{"key": "k", "version": "2"},
+		Retries:      retries,
+		RetryWaitMin: time.Nanosecond,
+		RetryWaitMax: time.Nanosecond,
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return
+}
+
+// okTransport answers every request with an empty 200 response.
+var okTransport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+})
+
+// retryTransport answers three requests out of four with a 503 response, so that every sent
+// request goes through three retries.
+func retryTransport() (transport roundTripperFunc) {
+	var sent int
+
+	transport = func(req *http.Request) (*http.Response, error) {
+		sent++
+
+		status := http.StatusServiceUnavailable
+
+		if sent%4 == 0 {
+			status = http.StatusOK
+		}
+
+		return &http.Response{
+			StatusCode:    status,
+			Header:        make(http.Header),
+			Body:          io.NopCloser(strings.NewReader("unavailable")),
+			ContentLength: int64(len("unavailable")),
+			Request:       req,
+		}, nil
+	}
+
+	return
+}
+
+func benchmarkBuild(client *Client) func() {
+	return func() {
+		_, _ = client.GET("/users").
+			SetHeader("Authorization", "Bearer token").
+			AddParam("page", "2").
+			Build()
+	}
+}
+
+func benchmarkHeaders(client *Client) func() {
+	return func() {
+		NewRequestBuilder(client, "", "/users").
+			SetHeader("Authorization", "Bearer token").
+			AddHeader("Accept-Language", "en").
+			SetHeaderIfAbsent("Accept", "text/html").
+			DelHeader("X-Client")
+	}
+}
+
+func benchmarkReusableBody(tb testing.TB) func() {
+	tb.Helper()
+
+	body, err := hqgoreaderutil.NewReusableReadCloser([]byte(strings.Repeat("a", 4<<10)))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	req := &Request{Request: &http.Request{Body: body}}
+
+	return func() {
+		_, _ = req.reusableBody()
+	}
+}
+
+func benchmarkRetryLoop(client *Client) func() {
+	return func() {
+		res, err := client.GET("/users").Send()
+		if err == nil {
+			res.Body.Close()
+		}
+	}
+}
+
+func BenchmarkRequestBuild(b *testing.B) {
+	run := benchmarkBuild(newBenchmarkClient(b, 0, okTransport))
+
+	b.ReportAllocs()
+
+	for range b.N {
+		run()
+	}
+}
+
+func BenchmarkHeaderApplication(b *testing.B) {
+	run := benchmarkHeaders(newBenchmarkClient(b, 0, okTransport))
+
+	b.ReportAllocs()
+
+	for range b.N {
+		run()
+	}
+}
+
+func BenchmarkReusableBodyRead(b *testing.B) {
+	run := benchmarkReusableBody(b)
+
+	b.ReportAllocs()
+
+	for range b.N {
+		run()
+	}
+}
+
+func BenchmarkRetryLoop(b *testing.B) {
+	run := benchmarkRetryLoop(newBenchmarkClient(b, 3, retryTransport()))
+
+	b.ReportAllocs()
+
+	for range b.N {
+		run()
+	}
+}
+
+// BenchmarkDrainBody drains retried response bodies from concurrent requests, as under heavy
+// retry load, through drainBody and through the io.Copy to io.Discard it replaced.
+func BenchmarkDrainBody(b *testing.B) {
+	const size = 16 << 10
+
+	content := []byte(strings.Repeat("a", size))
+
+	client := newBenchmarkClient(b, 0, okTransport)
+
+	client.cfg.RespReadLimit = size
+
+	req, err := client.GET("/users").Build()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// newResponse hides the WriterTo of the body, as network bodies have none.
+	newResponse := func() (res *http.Response) {
+		res = &http.Response{
+			Body:          io.NopCloser(struct{ io.Reader }{bytes.NewReader(content)}),
+			ContentLength: size,
+		}
+
+		return
+	}
+
+	b.Run("drainBody", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(size)
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				client.drainBody(req, newResponse())
+			}
+		})
+	})
+
+	b.Run("io.Copy", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(size)
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				res := newResponse()
+
+				_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, size))
+
+				res.Body.Close()
+			}
+		})
+	})
+}
+
+func TestAllocationBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation budgets do not hold under the race detector")
+	}
+
+	tests := []struct {
+		name   string
+		run    func() func()
+		budget float64
+	}{
+		{
+			name:   "request build",
+			run:    func() func() { return benchmarkBuild(newBenchmarkClient(t, 0, okTransport)) },
+			budget: buildAllocBudget,
+		},
+		{
+			name:   "header application",
+			run:    func() func() { return benchmarkHeaders(newBenchmarkClient(t, 0, okTransport)) },
+			budget: headerAllocBudget,
+		},
+		{
+			name:   "reusable body read",
+			run:    func() func() { return benchmarkReusableBody(t) },
+			budget: reusableBodyAllocBudget,
+		},
+		{
+			name:   "retry loop",
+			run:    func() func() { return benchmarkRetryLoop(newBenchmarkClient(t, 3, retryTransport())) },
+			budget: retryLoopAllocBudget,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if allocs := testing.AllocsPerRun(100, tt.run()); allocs > tt.budget {
+				t.Fatalf("got %.0f allocations, budget is %.0f", allocs, tt.budget)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrBudgetExhausted is the cause a Budget context is canceled with when a stop condition
+	// is met. The wrapping error describes the condition.
+	ErrBudgetExhausted = errors.New("budget exhausted")
+	// ErrBudgetStopped is the cause a Budget context is canceled with when Stop is called.
+	ErrBudgetStopped = errors.New("budget stopped")
+)
+
+// budgetContextKey is the context key under which a Budget context carries its Budget, so
+// that Client.Do reports the outcome of requests bound to it.
+const budgetContextKey ContextOverride = "budget"
+
+// BudgetConfiguration defines the stop conditions of a Budget. Zero values disable the
+// matching condition.
+type BudgetConfiguration struct {
+	MaxDuration             time.Duration // Maximum duration of the run.
+	MaxErrorRate            float64       // Maximum fraction (0-1) of requests failing with an error or a 5xx response.
+	MinRequests             int           // Number of requests observed before MaxErrorRate is evaluated.
+	MaxConsecutiveForbidden int           // Maximum number of consecutive 403 Forbidden responses (e.g., once a WAF blocks the scanner).
+}
+
+// BudgetStatistics summarizes the requests observed by a Budget.
+type BudgetStatistics struct {
+	Requests             int // Number of requests observed.
+	Failures             int // Number of requests failing with an error or a 5xx response.
+	ConsecutiveForbidden int // Number of consecutive 403 Forbidden responses up to the last request.
+}
+
+// Budget guards a batch of requests (e.g., a crawl or a scan) with stop conditions evaluated
+// across all of them. Requests bound to its context (see RequestBuilder.Context) are reported
+// automatically by Client.Do; once a condition is met the context is canceled, which cleanly
+// aborts outstanding requests and stops retries, and Err reports why the run stopped.
+type Budget struct {
+	cfg    BudgetConfiguration
+	ctx    context.Context //nolint:containedctx // The context requests of the run are bound to.
+	cancel context.CancelCauseFunc
+	timer  *time.Timer
+
+	mutex      sync.Mutex
+	statistics BudgetStatistics
+}
+
+// Context returns the context to bind the requests of the run to.
+//
+// Parameters: None.
+//
+// Returns:
+//   - ctx: The budget context.
+func (b *Budget) Context() (ctx context.Context) {
+	ctx = b.ctx
+
+	return
+}
+
+// Err returns why the run stopped: an error wrapping ErrBudgetExhausted, ErrBudgetStopped,
+// or the error of the parent context. It returns nil while the run goes on.
+//
+// Parameters: None.
+//
+// Returns:
+//   - err: The reason the run stopped.
+func (b *Budget) Err() (err error) {
+	if b.ctx.Err() == nil {
+		return
+	}
+
+	err = context.Cause(b.ctx)
+
+	return
+}
+
+// Statistics returns a summary of the requests observed so far.
+//
+// Parameters: None.
+//
+// Returns:
+//   - statistics: The statistics.
+func (b *Budget) Statistics() (statistics BudgetStatistics) {
+	b.mutex.Lock()
+
+	defer b.mutex.Unlock()
+
+	statistics = b.statistics
+
+	return
+}
+
+// Stop ends the run, canceling outstanding requests with ErrBudgetStopped. It must be called
+// once the run is over to release the resources of the budget.
+//
+// Parameters: None.
+//
+// Returns: None.
+func (b *Budget) Stop() {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+
+	b.cancel(ErrBudgetStopped)
+}
+
+// Observe records the outcome of a request and evaluates the stop conditions. Requests sent
+// by Client.Do with a budget context are observed automatically.
+//
+// Parameters:
+//   - res: The response of the request, nil if it failed.
+//   - err: The error the request failed with.
+//
+// Returns: None.
+func (b *Budget) Observe(res *http.Response, err error) {
+	// Requests aborted because the run stopped say nothing about the target.
+	if b.ctx.Err() != nil {
+		return
+	}
+
+	b.mutex.Lock()
+
+	defer b.mutex.Unlock()
+
+	b.statistics.Requests++
+
+	if err != nil || res != nil && res.StatusCode >= http.StatusInternalServerError {
+		b.statistics.Failures++
+	}
+
+	if err == nil && res != nil && res.StatusCode == http.StatusForbidden {
+		b.statistics.ConsecutiveForbidden++
+	} else {
+		b.statistics.ConsecutiveForbidden = 0
+	}
+
+	if b.cfg.MaxConsecutiveForbidden > 0 && b.statistics.ConsecutiveForbidden >= b.cfg.MaxConsecutiveForbidden {
+		b.cancel(fmt.Errorf("%w: %d consecutive 403 responses", ErrBudgetExhausted, b.statistics.ConsecutiveForbidden))
+
+		return
+	}
+
+	if b.cfg.MaxErrorRate > 0 && b.statistics.Requests >= max(b.cfg.MinRequests, 1) {
+		rate := float64(b.statistics.Failures) / float64(b.statistics.Requests)
+
+		if rate > b.cfg.MaxErrorRate {
+			b.cancel(fmt.Errorf("%w: error rate %.2f above %.2f", ErrBudgetExhausted, rate, b.cfg.MaxErrorRate))
+		}
+	}
+}
+
+// budgetFromContext returns the Budget a context was derived from, if any.
+//
+// Parameters:
+//   - ctx: The request context.
+//
+// Returns:
+//   - budget: The budget, nil if the context is not bound to one.
+func budgetFromContext(ctx context.Context) (budget *Budget) {
+	budget, _ = ctx.Value(budgetContextKey).(*Budget)
+
+	return
+}
+
+// NewBudget creates a Budget whose context derives from parent.
+//
+// Parameters:
+//   - parent: The parent context of the run.
+//   - cfg: The stop conditions.
+//
+// Returns:
+//   - budget: The new budget.
+func NewBudget(parent context.Context, cfg BudgetConfiguration) (budget *Budget) {
+	budget = &Budget{
+		cfg: cfg,
+	}
+
+	budget.ctx, budget.cancel = context.WithCancelCause(context.WithValue(parent, budgetContextKey, budget))
+
+	if cfg.MaxDuration > 0 {
+		budget.timer = time.AfterFunc(cfg.MaxDuration, func() {
+			budget.cancel(fmt.Errorf("%w: duration %s exceeded", ErrBudgetExhausted, cfg.MaxDuration))
+		})
+	}
+
+	return
+}
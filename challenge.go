@@ -0,0 +1,95 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// ErrAuthentication is returned when an authentication scheme fails to answer a challenge.
+var ErrAuthentication = errors.New("authentication failed")
+
+// AuthScheme answers the authentication challenges of one scheme, registered through
+// ClientConfiguration.AuthSchemes. Implementations must be safe for concurrent use.
+type AuthScheme interface {
+	// Scheme returns the name of the scheme answered (e.g., "Digest"), matched
+	// case-insensitively against challenges.
+	Scheme() (scheme string)
+
+	// Authorize returns the credentials answering a challenge to an attempt, sent in the
+	// Authorization header (Proxy-Authorization for 407 Proxy Authentication Required).
+	Authorize(ctx context.Context, attempt *http.Request, challenge headers.Challenge) (credentials string, err error)
+}
+
+// challengeAnswer holds the credentials answering the authentication challenge of an attempt.
+type challengeAnswer struct {
+	header      headers.Header // Header the credentials are sent in.
+	scheme      string         // Scheme of the challenge answered.
+	credentials string         // Credentials sent.
+}
+
+// answerChallenge answers the authentication challenge of an attempt rejected with 401
+// Unauthorized or 407 Proxy Authentication Required, using the first registered scheme (in
+// order of registration) the response offers. Attempts already sent with credentials are left
+// unanswered.
+//
+// Parameters:
+//   - attempt: The request of the attempt.
+//   - res: The response of the attempt.
+//
+// Returns:
+//   - answer: The credentials to send the request again with, nil if the challenge is not answered.
+//   - err: An error wrapping ErrAuthentication if the scheme fails to answer it.
+func (c *Client) answerChallenge(attempt *http.Request, res *http.Response) (answer *challengeAnswer, err error) {
+	if len(c.authSchemes) == 0 {
+		return
+	}
+
+	challengeHeader, credentialsHeader := headers.WWWAuthenticate, headers.Authorization
+
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+	case http.StatusProxyAuthRequired:
+		challengeHeader, credentialsHeader = headers.ProxyAuthenticate, headers.ProxyAuthorization
+	default:
+		return
+	}
+
+	if attempt.Header.Get(credentialsHeader.String()) != "" {
+		return
+	}
+
+	// Malformed challenges are ignored, as the client cannot answer them either way.
+	challenges, _ := headers.ParseChallenges(res.Header.Values(challengeHeader.String())...)
+
+	for _, scheme := range c.authSchemes {
+		for _, challenge := range challenges {
+			if !strings.EqualFold(challenge.Scheme, scheme.Scheme()) {
+				continue
+			}
+
+			var credentials string
+
+			credentials, err = scheme.Authorize(attempt.Context(), attempt, challenge)
+			if err != nil {
+				err = fmt.Errorf("%w: %s: %w", ErrAuthentication, scheme.Scheme(), err)
+
+				return
+			}
+
+			answer = &challengeAnswer{
+				header:      credentialsHeader,
+				scheme:      challenge.Scheme,
+				credentials: credentials,
+			}
+
+			return
+		}
+	}
+
+	return
+}
@@ -2,13 +2,18 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"go.source.hueristiq.com/http/headers"
 	"go.source.hueristiq.com/http/methods"
 	"go.source.hueristiq.com/retrier"
 	"go.source.hueristiq.com/retrier/backoff"
@@ -22,19 +27,32 @@ type Client struct {
 	HTTP2Client *http.Client
 
 	OnError ErrorHandler
+	OnRetry RetryHandler
 
 	RetryPolicy  RetryPolicy
 	RetryBackoff backoff.Backoff
 
 	BaseURL string
 	Headers map[string]string
-
-	requestCounter atomic.Uint32
-	cfg            *ClientConfiguration
+	Params  map[string]string
+
+	requestCounter     atomic.Uint32
+	authSchemes        []AuthScheme
+	isolatedTransports isolatedTransportCache
+	transparentRetries atomic.Uint64
+	altSvc             *altSvcCache
+	clockSkew          *clockSkewTracker
+	hostStats          *hostStatsTracker
+	dialStats          *dialStatsTracker
+	dnsPinner          *dnsPinner
+	caBundle           *caBundle
+	logger             Logger
+	cfg                *ClientConfiguration
 }
 
 // Do executes an HTTP request with the client, applying retry policies, error handling, and optional HTTP/2 fallback.
-// It supports digest authentication and keeps track of request metrics.
+// It supports digest authentication and keeps track of request metrics. Canceling the request
+// context (see RequestBuilder.Context) aborts the request and any pending retry.
 //
 // Parameters:
 //   - req: The HTTP request to be executed.
@@ -43,11 +61,62 @@ type Client struct {
 //   - res: The HTTP response from the request, or nil if the request failed.
 //   - err: Error encountered during the request or after exhausting retries.
 func (c *Client) Do(req *Request) (res *http.Response, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	c.assignRequestID(req)
+
+	started := time.Now()
+
+	// Retries are bounded by the request context, so that canceling it stops them, and by the
+	// client timeout. Attempts use the request context itself, as the response body is read
+	// after Do returns.
+	var ctx context.Context
+
+	var cancel context.CancelFunc
+
+	timeout := c.cfg.Timeout
+
+	if override, ok := requestTimeout(req.Context()); ok {
+		timeout = override
+	}
+
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(req.Context())
+	}
 
 	defer cancel()
 
-	retryMax := c.cfg.Retries
+	if !c.cfg.KeepURLUserinfo {
+		moveURLUserinfo(req)
+	}
+
+	c.applyBasicAuth(req)
+
+	if err = canonicalizeHost(req); err != nil {
+		err = fmt.Errorf("%s %s: %w", req.Method, redactedURL(req.URL), err)
+
+		return
+	}
+
+	if err = c.checkDestination(req); err != nil {
+		err = fmt.Errorf("%s %s: %w", req.Method, redactedURL(req.URL), err)
+
+		return
+	}
+
+	c.applyAcceptEncoding(req)
+
+	c.claimDecompression(req)
+
+	var entry *harEntry
+
+	if c.cfg.HARRecorder != nil {
+		entry = c.cfg.HARRecorder.start(req)
+	}
+
+	profile := c.retryProfile(req)
+
+	retryMax := profile.Retries
 
 	if ctxRetryMax := req.Context().Value(RetryMax); ctxRetryMax != nil {
 		if maxRetriesParsed, ok := ctxRetryMax.(int); ok {
@@ -55,24 +124,70 @@ func (c *Client) Do(req *Request) (res *http.Response, err error) {
 		}
 	}
 
+	// retryAfter is the delay requested by the Retry-After header of the last retried response,
+	// used by the backoff below instead of the configured one.
+	var retryAfter time.Duration
+
+	// retried describes the last retried attempt, reported to the retry handlers by the backoff below.
+	var retried RetryInfo
+
+	jitter := profile.RetryJitter
+
+	if override, ok := requestJitter(req.Context()); ok {
+		jitter = override
+	}
+
+	// failure annotates the error returned if the request fails, with the last response received.
+	failure := RequestError{Method: req.Method, URL: redactedURL(req.URL)}
+
+	// previousDelay is the last delay waited, from which JitterDecorrelated derives the next one.
+	previousDelay := profile.RetryWaitMin
+
 	res, err = retrier.RetryWithData(ctx, func() (res *http.Response, err error) {
-		res, err = c.HTTPClient.Do(req.Request)
+		attemptCtx, recorder := withTimingTrace(req.Context())
+		attemptCtx, monitor := c.withRateMonitor(attemptCtx, &req.Metrics)
+
+		sent := time.Now()
+
+		if c.cfg.FallbackPolicy == FallbackHTTP2First {
+			res, monitor, err = c.sendHedged(attemptCtx, c.HTTP2Client, req, monitor)
+		} else {
+			res, monitor, err = c.sendHedged(attemptCtx, c.HTTPClient, req, monitor)
+		}
+
+		if err == nil && c.clockSkew != nil {
+			c.clockSkew.observe(req.URL.Host, res.Header, sent, time.Now())
+		}
+
+		if c.hostStats != nil {
+			c.hostStats.observe(req.URL.Host, res, err, time.Since(sent))
+		}
 
 		// Check if the request should be retried based on the response or error.
-		retry, checkErr := c.RetryPolicy(req.Context(), err)
+		retry, checkErr := c.RetryPolicy(req.Context(), res, err)
 
 		// Fallback to HTTP/2 if HTTP/1.x transport encounters specific errors.
-		if err != nil && strings.Contains(err.Error(), "net/http: HTTP/1.x transport connection broken: malformed HTTP version \"HTTP/2\"") {
-			res, err = c.HTTP2Client.Do(req.Request)
+		if c.cfg.FallbackPolicy == FallbackOnProtocolError && isHTTP2ProtocolError(err) {
+			c.logger.Info("falling back to HTTP/2", requestLogArgs(req, "error", err)...)
+
+			res, err = c.sendAttempt(attemptCtx, c.HTTP2Client, req, monitor)
 
-			retry, checkErr = c.RetryPolicy(req.Context(), err)
+			retry, checkErr = c.RetryPolicy(req.Context(), res, err)
 		}
 
+		monitor.settle(res, err)
+
+		req.Metrics.Timings = append(req.Metrics.Timings, recorder.result())
+
 		if err != nil {
 			req.Metrics.Failures++
 		}
 
-		if !retry {
+		// A response retried on its status is returned as is once no attempt follows, so that the
+		// caller can still read it.
+		final := req.Metrics.Retries >= retryMax && err == nil
+
+		if !retry || final {
 			if checkErr != nil {
 				err = checkErr
 			}
@@ -84,40 +199,315 @@ func (c *Client) Do(req *Request) (res *http.Response, err error) {
 
 		req.Metrics.Retries++
 
+		retried = RetryInfo{Attempt: req.Metrics.Retries, Err: err}
+
 		if err == nil && res != nil {
+			retried.StatusCode = res.StatusCode
+
+			retryAfter = c.retryAfter(res)
+
+			c.recordFailedResponse(&failure, res)
+
 			c.drainBody(req, res)
+
+			// The response is retried on its status: report it as an error so that it is retried.
+			err = fmt.Errorf("%w: %s", ErrRetryableStatus, res.Status)
 		}
 
 		return
 	},
 		retrier.WithMaxRetries(retryMax),
-		retrier.WithMaxDelay(c.cfg.RetryWaitMax),
-		retrier.WithMinDelay(c.cfg.RetryWaitMin),
+		retrier.WithMaxDelay(profile.RetryWaitMax),
+		retrier.WithMinDelay(profile.RetryWaitMin),
+		retrier.WithBackoff(func(minDelay, maxDelay time.Duration, attempt int) (delay time.Duration) {
+			if retryAfter > 0 {
+				delay, retryAfter = retryAfter, 0
+			} else {
+				delay = jitter.apply(profile.RetryBackoff(minDelay, maxDelay, attempt), minDelay, maxDelay, previousDelay)
+			}
+
+			previousDelay = delay
+
+			c.notifyRetry(req, retried, delay)
+
+			return
+		}),
 	)
 
+	if err == nil && res != nil {
+		fingerprinter, raw := c.startFingerprint(res)
+
+		if req.decompress {
+			c.decompressResponse(res)
+		}
+
+		finishFingerprint(res, fingerprinter, raw)
+
+		req.Metrics.Protocol = res.Proto
+		req.Metrics.ContentEncoding = responseContentEncoding(res.Uncompressed, res.Header.Get(headers.ContentEncoding.String()))
+		req.Metrics.ServerTiming, _ = headers.ParseServerTiming(res.Header.Values(headers.ServerTiming.String())...)
+
+		if c.altSvc != nil {
+			c.altSvc.record(req.URL, res.Header)
+		}
+
+		c.transformResponseBody(req, res)
+	}
+
+	if entry != nil {
+		c.cfg.HARRecorder.finish(entry, req, res, err)
+	}
+
+	if budget := budgetFromContext(req.Context()); budget != nil {
+		budget.Observe(res, err)
+	}
+
 	if c.OnError != nil {
 		c.closeIdleConnections()
 
-		res, err = c.OnError(res, err, c.cfg.Retries+1)
+		res, err = c.OnError(res, err, retryMax+1)
 
 		return
 	}
 
 	if err != nil {
 		if res != nil {
+			// Responses retried on their status were recorded before their body was drained.
+			if !errors.Is(err, ErrRetryableStatus) {
+				c.recordFailedResponse(&failure, res)
+			}
+
 			res.Body.Close()
+
+			res = nil
 		}
 
 		c.closeIdleConnections()
 
-		err = fmt.Errorf("%s %s giving up after %d attempts: %w", req.Method, req.URL, c.cfg.Retries+1, err)
+		failure.Attempts = len(req.Metrics.Timings)
+		failure.Elapsed = time.Since(started)
+		failure.Err = err
+
+		err = &failure
+
+		c.logger.Error("request failed", requestLogArgs(req, "error", err)...)
+	}
+
+	return
+}
+
+// notifyRetry reports a retry to the client and request retry handlers.
+//
+// Parameters:
+//   - req: The request being retried.
+//   - info: The retried attempt.
+//   - wait: The time waited before the next attempt.
+//
+// Returns: None.
+func (c *Client) notifyRetry(req *Request, info RetryInfo, wait time.Duration) {
+	info.Wait = wait
+
+	c.logger.Info("retrying request", requestLogArgs(req, "attempt", info.Attempt, "wait", wait, "status", info.StatusCode, "error", info.Err)...)
+
+	if c.OnRetry != nil {
+		c.OnRetry(req, info)
+	}
+
+	if req.onRetry != nil {
+		req.onRetry(req, info)
+	}
+}
+
+// retryAfter returns the delay a retried response asks for through its Retry-After header,
+// capped to ClientConfiguration.RetryAfterMax, or zero to use the configured backoff.
+//
+// Parameters:
+//   - res: The retried response.
+//
+// Returns:
+//   - delay: The delay before the next attempt, zero if the response does not ask for one.
+func (c *Client) retryAfter(res *http.Response) (delay time.Duration) {
+	if c.cfg.IgnoreRetryAfter {
+		return
+	}
+
+	value := res.Header.Get(headers.RetryAfter.String())
+
+	if value == "" {
+		return
+	}
+
+	delay, err := headers.ParseRetryAfter(value, time.Now())
+	if err != nil {
+		delay = 0
+
+		return
+	}
+
+	maxDelay := c.cfg.RetryAfterMax
+
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryAfterMax
+	}
+
+	delay = min(delay, maxDelay)
+
+	return
+}
+
+// sendAttempt prepares an attempt of a request (body transformation, access token, DPoP proof)
+// and sends it through the given HTTP client. An attempt challenged for authentication is sent
+// again once with the credentials of a registered AuthScheme, an attempt whose DPoP proof is
+// rejected for lacking the server's nonce is sent again once with the nonce, an attempt whose
+// access token is rejected is sent again once with a fresh token (see TokenInvalidator), and
+// an attempt failing with an HTTP/2 GOAWAY or stream error is sent again on a new stream (see
+// isTransparentlyRetryable).
+//
+// Parameters:
+//   - ctx: The context of the attempt.
+//   - httpClient: The HTTP client to send the attempt through.
+//   - req: The request.
+//   - monitor: The rate monitor of the attempt, nil if disabled.
+//
+// Returns:
+//   - res: The response, nil if the attempt failed.
+//   - err: The error the attempt failed with.
+func (c *Client) sendAttempt(ctx context.Context, httpClient *http.Client, req *Request, monitor *rateMonitor) (res *http.Response, err error) {
+	resent, refreshed, replays := false, false, 0
+
+	// The credentials answering an authentication challenge, once answered.
+	var answer *challengeAnswer
+
+	for {
+		attempt := req.Request.WithContext(ctx)
+
+		// Every attempt gets its own header, so that hooks and DPoP proofs of concurrent
+		// (hedged) attempts do not race.
+		attempt.Header = req.Request.Header.Clone()
+
+		switch {
+		case req.acceptEncoding != "":
+			attempt.Header.Set(headers.AcceptEncoding.String(), req.acceptEncoding)
+		case req.decompress:
+			attempt.Header.Set(headers.AcceptEncoding.String(), "gzip")
+		}
+
+		c.transformRequestBody(req, attempt)
+
+		if answer != nil {
+			attempt.Header.Set(answer.header.String(), answer.credentials)
+		}
+
+		var token string
+
+		if token, err = c.applyToken(attempt); err != nil {
+			return
+		}
+
+		if c.cfg.OnRequest != nil {
+			if err = c.cfg.OnRequest(req, attempt); err != nil {
+				return
+			}
+		}
+
+		if c.cfg.DPoP != nil {
+			if err = c.cfg.DPoP.sign(attempt); err != nil {
+				return
+			}
+		}
+
+		c.logAttempt(attempt)
+
+		if err = c.dumpRequest(req, attempt); err != nil {
+			return
+		}
+
+		res, err = c.send(httpClient, attempt, monitor)
+
+		res, err = c.dumpResponse(req, res, err)
+
+		c.observeAttempt(req, res, err)
+
+		if replays < maxTransparentRetries && ctx.Err() == nil && isTransparentlyRetryable(req, err) {
+			replays++
+
+			c.transparentRetries.Add(1)
+
+			continue
+		}
+
+		if err != nil {
+			return
+		}
+
+		answered := answer != nil
+
+		if answered {
+			err = c.verifyNegotiation(attempt, res, answer)
+		} else {
+			answer, err = c.answerChallenge(attempt, res)
+		}
+
+		if err != nil {
+			c.drainBody(req, res)
+
+			res = nil
+
+			return
+		}
+
+		// A DPoP nonce challenge is answered before the token is suspected, both being 401s.
+		switch {
+		case !answered && answer != nil:
+		case c.cfg.DPoP != nil && c.cfg.DPoP.observe(attempt, res) && !resent:
+			resent = true
+		case !refreshed && c.invalidateToken(token, res):
+			refreshed = true
+		default:
+			return
+		}
+
+		c.drainBody(req, res)
 	}
+}
+
+// send sends a single attempt through the given HTTP client, applying the request timeout and
+// TLS overrides and reporting the errors of the rate monitor and of the response header limits.
+//
+// Parameters:
+//   - httpClient: The HTTP client to send the attempt through.
+//   - attempt: The request of the attempt.
+//   - monitor: The rate monitor of the attempt, nil if disabled.
+//
+// Returns:
+//   - res: The response, nil if the attempt failed.
+//   - err: The error the attempt failed with.
+func (c *Client) send(httpClient *http.Client, attempt *http.Request, monitor *rateMonitor) (res *http.Response, err error) {
+	// A per request timeout replaces the client timeout on a copy of the client, sharing its transport.
+	if timeout, ok := requestTimeout(attempt.Context()); ok {
+		overridden := *httpClient
+
+		overridden.Timeout = timeout
+
+		httpClient = &overridden
+	}
+
+	httpClient, err = c.isolateTLS(attempt.Context(), httpClient)
+	if err != nil {
+		return
+	}
+
+	res, err = httpClient.Do(attempt)
+
+	err = monitor.err(err)
+
+	res, err = c.checkResponseHeaders(res, err)
 
 	return
 }
 
 func (c *Client) GET(URL string) (builder *RequestBuilder) {
-	builder = NewRequestBuilder(c, methods.Get.String(), URL)
+	builder = NewRequestBuilder(c, methods.Get, URL)
 
 	return
 }
@@ -143,7 +533,7 @@ func (c *Client) GET(URL string) (builder *RequestBuilder) {
 // }
 
 func (c *Client) HEAD(URL string) (builder *RequestBuilder) {
-	builder = NewRequestBuilder(c, methods.Head.String(), URL)
+	builder = NewRequestBuilder(c, methods.Head, URL)
 
 	return
 }
@@ -169,7 +559,7 @@ func (c *Client) HEAD(URL string) (builder *RequestBuilder) {
 // }
 
 func (c *Client) POST(URL string) (builder *RequestBuilder) {
-	builder = NewRequestBuilder(c, methods.Post.String(), URL)
+	builder = NewRequestBuilder(c, methods.Post, URL)
 
 	return
 }
@@ -228,7 +618,167 @@ func (c *Client) setKillIdleConnections() {
 	}
 }
 
-// closeIdleConnections closes idle connections in the HTTP client if the request count reaches a certain threshold.
+// ownHTTPClient copies an HTTP client, cloning its transport if it is an *http.Transport (or
+// nil, standing for http.DefaultTransport) so that it can be configured without affecting
+// other users of the original one.
+//
+// Parameters:
+//   - httpClient: The HTTP client to copy.
+//
+// Returns:
+//   - owned: The copy.
+//   - err: An error if HTTP/2 cannot be configured on the cloned transport.
+func ownHTTPClient(httpClient *http.Client) (owned *http.Client, err error) {
+	copied := *httpClient
+
+	owned = &copied
+
+	base := copied.Transport
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return
+	}
+
+	cloned := transport.Clone()
+
+	// The HTTP/2 support of a transport configured by http2.ConfigureTransport is cloned along
+	// with its connection pool: it is configured afresh.
+	if _, ok := cloned.TLSNextProto["h2"]; ok {
+		cloned.TLSNextProto = nil
+
+		err = http2.ConfigureTransport(cloned)
+	}
+
+	owned.Transport = cloned
+
+	return
+}
+
+// ErrUnsupportedTransport is returned by NewClient when the configuration asks for features
+// that wrap the dialer of an *http.Transport, but the HTTP client has another transport.
+var ErrUnsupportedTransport = errors.New("configuration needs an *http.Transport")
+
+// transportFeatures lists the configured features that need an *http.Transport, so that
+// NewClient fails rather than silently dropping them.
+//
+// Parameters: None.
+//
+// Returns:
+//   - features: The names of the configuration fields.
+func (c *Client) transportFeatures() (features []string) {
+	if c.cfg.SSRFProtection != nil {
+		features = append(features, "SSRFProtection")
+	}
+
+	if c.cfg.DNSPinning != nil {
+		features = append(features, "DNSPinning")
+	}
+
+	if len(c.cfg.StaticHosts) > 0 {
+		features = append(features, "StaticHosts")
+	}
+
+	if len(c.cfg.ProxyProtocol) > 0 {
+		features = append(features, "ProxyProtocol")
+	}
+
+	if c.cfg.HappyEyeballs != nil {
+		features = append(features, "HappyEyeballs")
+	}
+
+	if c.cfg.AltSvc {
+		features = append(features, "AltSvc")
+	}
+
+	if c.cfg.MinHeaderRate > 0 {
+		features = append(features, "MinHeaderRate")
+	}
+
+	if c.cfg.MaxResponseHeaderBytes > 0 {
+		features = append(features, "MaxResponseHeaderBytes")
+	}
+
+	if c.cfg.TLS.overridesTransport() {
+		features = append(features, "TLS")
+	}
+
+	return
+}
+
+// configureTransport applies the client's connection level features to the transport of
+// the given HTTP client. Transports other than *http.Transport are left untouched, which
+// fails if features needing one are configured.
+//
+// Parameters:
+//   - httpClient: The HTTP client whose transport is configured.
+//
+// Returns:
+//   - err: An error wrapping ErrUnsupportedTransport if features cannot be applied.
+func (c *Client) configureTransport(httpClient *http.Client) (err error) {
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		if features := c.transportFeatures(); len(features) > 0 {
+			err = fmt.Errorf("%w: %s with %T", ErrUnsupportedTransport, strings.Join(features, ", "), httpClient.Transport)
+		}
+
+		return
+	}
+
+	dial := transport.DialContext
+
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	// The SSRF protection wraps the dialer first so that it checks the address actually dialed,
+	// including Alt-Svc alternatives and static hosts. Happy Eyeballs resolves hosts itself and enforces it on
+	// the addresses it races.
+	switch {
+	case c.cfg.HappyEyeballs != nil:
+		dial = c.cfg.HappyEyeballs.dialContext(dial, c.cfg.SSRFProtection, c.dialStats)
+	case c.cfg.SSRFProtection != nil:
+		dial = c.cfg.SSRFProtection.dialContext(dial)
+	}
+
+	// Pinned addresses are dialed through the SSRF protection, which checks them as well.
+	if c.dnsPinner != nil {
+		dial = c.dnsPinner.dialContext(dial)
+	}
+
+	if len(c.cfg.StaticHosts) > 0 {
+		dial = staticHostsDialContext(c.cfg.StaticHosts, dial)
+	}
+
+	if len(c.cfg.ProxyProtocol) > 0 {
+		dial = proxyProtocolDialContext(c.cfg.ProxyProtocol, dial)
+	}
+
+	if c.altSvc != nil {
+		dial = c.altSvc.dialContext(dial)
+	}
+
+	if c.cfg.MinHeaderRate > 0 {
+		dial = countingDialContext(dial)
+	}
+
+	transport.DialContext = dial
+
+	if c.cfg.MaxResponseHeaderBytes > 0 {
+		transport.MaxResponseHeaderBytes = c.cfg.MaxResponseHeaderBytes
+	}
+
+	c.configureTLS(transport)
+
+	return
+}
+
+// closeIdleConnections closes idle connections of both the HTTP/1.x and HTTP/2 clients
+// if the request count reaches a certain threshold.
 //
 // Parameters: None.
 //
@@ -239,13 +789,29 @@ func (c *Client) closeIdleConnections() {
 			c.requestCounter.Add(1)
 		} else {
 			c.requestCounter.Store(0)
-			c.HTTPClient.CloseIdleConnections()
+			c.CloseIdleConnections()
 		}
 	}
 }
 
-// drainBody drains and discards the response body to prevent connection reuse issues.
-// It also closes the response body.
+// CloseIdleConnections closes any idle connections kept by both the HTTP/1.x and the HTTP/2
+// clients, and by the transports isolating TLS overrides. It does not interrupt connections
+// currently in use.
+//
+// Parameters: None.
+//
+// Returns: None.
+func (c *Client) CloseIdleConnections() {
+	c.HTTPClient.CloseIdleConnections()
+	c.HTTP2Client.CloseIdleConnections()
+	c.isolatedTransports.closeIdleConnections()
+}
+
+// drainBody drains and discards the response body so that the underlying connection can be
+// reused, then closes the body. Draining is skipped when the connection will not be reused
+// anyway (the request or response asks for it to be closed, keep-alives are disabled, or the
+// body is larger than RespReadLimit), and stops early if the request's context is done.
+// Reads go through pooled buffers to keep allocations flat under heavy retry load.
 //
 // Parameters:
 //   - req: The request whose body is being drained.
@@ -253,33 +819,187 @@ func (c *Client) closeIdleConnections() {
 //
 // Returns: None.
 func (c *Client) drainBody(req *Request, resp *http.Response) {
-	_, err := io.Copy(io.Discard, io.LimitReader(resp.Body, c.cfg.RespReadLimit))
-	if err != nil {
-		req.Metrics.DrainErrors++
+	defer resp.Body.Close()
+
+	if !c.isConnectionReusable(req, resp) {
+		return
+	}
+
+	buf, _ := drainBufferPool.Get().(*[]byte)
+
+	defer drainBufferPool.Put(buf)
+
+	remaining := c.cfg.RespReadLimit
+
+	for remaining > 0 {
+		if req.Context().Err() != nil {
+			return
+		}
+
+		chunk := *buf
+
+		if int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+
+		n, err := resp.Body.Read(chunk)
+
+		remaining -= int64(n)
+
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		if err != nil {
+			req.Metrics.DrainErrors++
+
+			return
+		}
+	}
+}
+
+// isConnectionReusable reports whether the connection a response was received on can go
+// back to the pool once its body is drained.
+//
+// Parameters:
+//   - req: The request the response was received for.
+//   - resp: The response.
+//
+// Returns:
+//   - reusable: Whether draining the body allows the connection to be reused.
+func (c *Client) isConnectionReusable(req *Request, resp *http.Response) (reusable bool) {
+	if req.Close || resp.Close {
+		return
+	}
+
+	if resp.ContentLength > c.cfg.RespReadLimit {
+		return
+	}
+
+	if transport, ok := c.HTTPClient.Transport.(*http.Transport); ok && transport.DisableKeepAlives {
+		return
 	}
 
-	resp.Body.Close()
+	reusable = true
+
+	return
 }
 
+// drainBufferPool holds the buffers used to drain response bodies.
+var drainBufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 8*1024)
+
+		return &buf
+	},
+}
+
+// DefaultRetryAfterMax is the cap on the delay honored from Retry-After headers when
+// ClientConfiguration.RetryAfterMax is not set.
+const DefaultRetryAfterMax = time.Minute
+
 // ClientConfiguration defines the configuration for an HTTP client.
 // This includes settings for retry logic, timeouts, backoff strategies, and connection handling.
 type ClientConfiguration struct {
-	HTTPClient *http.Client
+	HTTPClient    *http.Client
+	BaseTransport http.RoundTripper // Transport (e.g., an instrumented or caching one) the HTTP/1.x client is built on, with the client timeouts still applied. Ignored if HTTPClient is set. The HTTP/2 fallback client keeps its own transport. Features wrapping the dialer or TLS configuration fail with ErrUnsupportedTransport unless it is an *http.Transport.
 
 	RetryPolicy  RetryPolicy     // Function to determine retry logic for failed requests.
 	Retries      int             // Maximum number of retry attempts for requests.
 	RetryWaitMin time.Duration   // Minimum wait time between retries.
 	RetryWaitMax time.Duration   // Maximum wait time between retries.
 	RetryBackoff backoff.Backoff // Backoff strategy for retrying requests.
+	RetryJitter  Jitter          // Randomization applied to the backoff delays, none if empty.
+
+	RetryProfile      string            // Name of a RetryProfiles entry replacing the retry settings above.
+	HostRetryProfiles map[string]string // Per host (lowercased hostname) names of RetryProfiles entries, overriding RetryProfile.
+
+	OnRetry        RetryHandler     // Handler called before every retry, e.g. to log retries or emit metrics.
+	OnRequest      RequestHook      // Hook called before every attempt is sent, e.g. to audit requests or inject headers.
+	OnResponse     ResponseHook     // Hook called with the response of every attempt.
+	OnAttemptError AttemptErrorHook // Hook called with the error of every failed attempt. Unlike Client.OnError, it does not change the outcome.
+
+	RequestIDHeader string // Header (e.g., headers.XRequestID) carrying an ID generated per request and reused across retries (see ResponseRequestID). Empty disables it.
+	Traceparent     bool   // Whether to send a W3C traceparent header per request, its trace ID matching the request ID when possible.
+
+	MaxErrorBodyBytes int64 // Number of bytes of the last response body included in the errors of failed requests (see RequestError). Zero includes none.
+
+	Logger Logger // Structured logger attempts, retries and fallbacks are logged to, with sensitive headers redacted (see SensitiveHeaders). Nil disables logging.
+
+	HARRecorder *HARRecorder // Recorder capturing every exchange for export as a HAR archive. Nil disables recording.
+
+	DumpRequest      DumpHook // Hook called with the raw request of every attempt. Nil disables request dumps.
+	DumpResponse     DumpHook // Hook called with the raw response of every attempt. Nil disables response dumps.
+	MaxDumpBodyBytes int64    // Number of bytes of bodies included in dumps. Zero includes no bodies.
+
+	FingerprintBodies         bool // Whether to hash response bodies as they are read, before and after decoding (see ResponseFingerprint).
+	FingerprintHTMLWhitespace bool // Whether to also hash HTML bodies with whitespace normalized, when FingerprintBodies is set.
+
+	RetryAfterMax    time.Duration // Cap on the delay honored from Retry-After headers, DefaultRetryAfterMax if zero.
+	IgnoreRetryAfter bool          // Whether to always use the backoff strategy, ignoring Retry-After headers.
 
 	BaseURL string
 	Timeout time.Duration // Global timeout for the HTTP client.
 	Headers map[string]string
+	Params  map[string]string // Default query parameters added to every request.
+
+	TokenProvider TokenProvider // Provider of the access token set in the Authorization header of every attempt, refreshed once on 401 Unauthorized if it implements TokenInvalidator. Nil disables it.
+
+	BasicAuth *BasicAuth // Credentials sent with the Basic scheme by requests without an Authorization header. Nil disables it.
+
+	AuthSchemes []AuthScheme      // Schemes answering the challenges of 401 and 407 responses, once per request, in order of preference.
+	Negotiate   NegotiateProvider // Credential source answering Negotiate (SPNEGO, e.g. Kerberos) challenges of 401 Unauthorized responses. Nil disables it.
 
 	KillIdleConn  bool  // Whether to close idle connections after each request.
 	RespReadLimit int64 // Limit for reading response bodies during draining.
 
 	NoAdjustTimeout bool // Flag to prevent automatic adjustment of per-request timeouts.
+
+	FallbackPolicy FallbackPolicy // When to fall back to (or start with) the HTTP/2 client.
+
+	IgnoreProxyEnvironment bool       // Whether to ignore the proxy environment variables (HTTP_PROXY, HTTPS_PROXY, NO_PROXY) of the default transports.
+	ProxyAuth              *BasicAuth // Credentials sent to the proxy with the Basic scheme, for CONNECT tunnels and proxied HTTP requests, in place of proxy URL userinfo. Nil keeps the userinfo.
+
+	KeepURLUserinfo bool // Whether to leave URL credentials as is instead of moving them into an Authorization header.
+
+	AltSvc         bool // Whether to remember Alt-Svc advertised alternatives and connect to them for subsequent requests.
+	TrackClockSkew bool // Whether to measure per host clock skew from response Date headers (see Client.ClockSkew).
+	TrackHostStats bool // Whether to collect per host rolling success, error and latency statistics (see Client.HostStats).
+
+	MinHeaderRate int64         // Minimum rate, in bytes per second, at which response headers must arrive once the request is written. Zero disables the check.
+	MinBodyRate   int64         // Minimum rate, in bytes per second, at which the response body must arrive. Zero disables the check.
+	MinRateWindow time.Duration // Window over which the minimum rates are measured, DefaultMinRateWindow if zero.
+
+	MaxResponseHeaderBytes int64 // Limit on the size of response headers, failing larger ones with ErrResponseHeaderTooLarge. Zero keeps the transport's limit.
+	MaxResponseHeaders     int   // Limit on the number of response header field lines, failing responses with more with ErrTooManyResponseHeaders. Zero disables the check.
+
+	MaxBodyBytes    int64         // Size past which a response body is aborted with ErrTarpit. Zero disables the check.
+	MaxBodyDuration time.Duration // Time past which a still streaming response body is aborted with ErrTarpit. Zero disables the check.
+
+	AcceptEncodings map[string][]string // Per host (lowercased hostname) content codings to advertise instead of gzip, e.g. to stop advertising br to a host mis-serving it. An empty list advertises identity only.
+
+	MaxDecompressedBytes  int64   // Size past which a decoded gzip response body fails with ErrDecompressionBomb. Zero disables the check.
+	MaxDecompressionRatio float64 // Ratio of decoded to encoded size past which a gzip response body (over 1 MiB decoded) fails with ErrDecompressionBomb. Zero disables the check.
+
+	SSRFProtection *SSRFProtection // Address ranges refused with ErrForbiddenDestination, for URLs coming from untrusted input. Nil disables the protection.
+	DNSPinning     *DNSPinning     // Pinning of the addresses hosts resolve to, refusing public hosts moving to internal addresses with ErrDNSRebinding. Nil disables pinning.
+	AllowedSchemes []string        // URL schemes allowed (e.g., "https" only), refusing others with ErrForbiddenDestination. Empty allows any.
+	AllowedPorts   []PortRange     // Ports allowed, refusing others with ErrForbiddenDestination. Empty allows any.
+
+	HedgeDelay time.Duration // Delay after which a duplicate of an idempotent request without a body is sent if no response arrived, the first response winning. Zero disables hedging.
+
+	StaticHosts map[string]string // Per host (lowercased hostname) IP addresses dialed instead of resolving the host, like entries of a hosts file.
+
+	ProxyProtocol map[string]*ProxyProtocol // Per host (lowercased hostname) PROXY protocol headers sent at the start of connections.
+
+	HappyEyeballs *HappyEyeballs // Explicit RFC 8305 racing of connection attempts across address families (see Client.DialStats). Nil leaves it to net.Dialer.
+
+	RequestBodyTransformers  []BodyTransformer // Stages transforming every request body, in order, before the request's own.
+	ResponseBodyTransformers []BodyTransformer // Stages transforming every final response body, in order, before the request's own.
+
+	DPoP *DPoPSigner // Signer adding DPoP proofs (RFC 9449) to every request. Nil disables DPoP.
+
+	TLS TLSConfiguration // TLS settings, such as additional trusted certificate authorities.
 }
 
 // NewClient creates a new HTTP client based on the provided configuration.
@@ -300,11 +1020,26 @@ func NewClient(cfg *ClientConfiguration) (client *Client, err error) {
 		client.HTTPClient = DefaultHTTPClient()
 	}
 
+	if cfg.BaseTransport != nil {
+		client.HTTPClient = &http.Client{
+			Transport: cfg.BaseTransport,
+		}
+	}
+
 	if cfg.HTTPClient != nil {
 		client.HTTPClient = cfg.HTTPClient
 	}
 
-	client.HTTP2Client = DefaultHTTPClient()
+	// The HTTP client and transport given by the caller (possibly http.DefaultTransport) are
+	// copied, so that the features configured below do not leak into their other users.
+	if cfg.HTTPClient != nil || cfg.BaseTransport != nil {
+		if client.HTTPClient, err = ownHTTPClient(client.HTTPClient); err != nil {
+			return
+		}
+	}
+
+	// The HTTP/2 client is pooled so that the HTTP/2 fallback in Do reuses multiplexed connections.
+	client.HTTP2Client = DefaultPooledClient()
 
 	HTTP2ClientTransport, ok := client.HTTP2Client.Transport.(*http.Transport)
 	if !ok {
@@ -315,12 +1050,42 @@ func NewClient(cfg *ClientConfiguration) (client *Client, err error) {
 		return
 	}
 
+	if err = checkStaticHosts(cfg.StaticHosts); err != nil {
+		return
+	}
+
+	if err = checkProxyProtocols(cfg.ProxyProtocol); err != nil {
+		return
+	}
+
+	profiles := []string{cfg.RetryProfile}
+
+	for _, name := range cfg.HostRetryProfiles {
+		profiles = append(profiles, name)
+	}
+
+	for _, name := range profiles {
+		if _, ok := RetryProfiles[name]; name != "" && !ok {
+			err = fmt.Errorf("%w: %q", ErrUnknownRetryProfile, name)
+
+			return
+		}
+	}
+
 	client.RetryPolicy = DefaultRetryPolicy()
 
 	if cfg.RetryPolicy != nil {
 		client.RetryPolicy = cfg.RetryPolicy
 	}
 
+	client.OnRetry = cfg.OnRetry
+
+	client.logger = nopLogger{}
+
+	if cfg.Logger != nil {
+		client.logger = cfg.Logger
+	}
+
 	client.RetryBackoff = backoff.Exponential()
 
 	if cfg.RetryBackoff != nil {
@@ -338,9 +1103,69 @@ func NewClient(cfg *ClientConfiguration) (client *Client, err error) {
 
 	client.cfg = cfg
 
+	if cfg.AltSvc {
+		client.altSvc = newAltSvcCache()
+	}
+
+	if cfg.TrackClockSkew {
+		client.clockSkew = newClockSkewTracker()
+	}
+
+	if cfg.TrackHostStats {
+		client.hostStats = newHostStatsTracker()
+	}
+
+	if cfg.HappyEyeballs != nil {
+		client.dialStats = newDialStatsTracker()
+	}
+
+	if cfg.DNSPinning != nil {
+		client.dnsPinner = newDNSPinner(cfg.DNSPinning)
+	}
+
+	client.authSchemes = slices.Clone(cfg.AuthSchemes)
+
+	if cfg.Negotiate != nil {
+		client.authSchemes = append(client.authSchemes, &negotiateAuthScheme{provider: cfg.Negotiate})
+	}
+
+	if len(cfg.TLS.CABundlePaths) > 0 {
+		client.caBundle, err = newCABundle(cfg.TLS.RootCAs, cfg.TLS.CABundlePaths, cfg.TLS.CABundleReloadInterval)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, httpClient := range []*http.Client{client.HTTPClient, client.HTTP2Client} {
+		if err = client.configureProxy(httpClient); err != nil {
+			return
+		}
+	}
+
+	for _, httpClient := range []*http.Client{client.HTTPClient, client.HTTP2Client} {
+		if err = client.configureTransport(httpClient); err != nil {
+			return
+		}
+	}
+
+	client.configureRedirects(client.HTTPClient)
+	client.configureRedirects(client.HTTP2Client)
+
 	client.setKillIdleConnections()
 
+	client.BaseURL = cfg.BaseURL
+
 	client.Headers = make(map[string]string)
 
+	for k, v := range cfg.Headers {
+		client.Headers[k] = v
+	}
+
+	client.Params = make(map[string]string)
+
+	for k, v := range cfg.Params {
+		client.Params[k] = v
+	}
+
 	return
 }
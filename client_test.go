@@ -0,0 +1,410 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(req *http.Request) (res *http.Response, err error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	return f(req)
+}
+
+func TestNewClientRejectsUnsupportedTransport(t *testing.T) {
+	custom := &http.Client{
+		Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("unused")
+		}),
+	}
+
+	base := custom.Transport
+
+	tests := []struct {
+		name    string
+		cfg     *ClientConfiguration
+		wantErr error
+	}{
+		{
+			name: "no transport feature",
+			cfg:  &ClientConfiguration{HTTPClient: custom},
+		},
+		{
+			name:    "SSRF protection",
+			cfg:     &ClientConfiguration{HTTPClient: custom, SSRFProtection: &SSRFProtection{}},
+			wantErr: ErrUnsupportedTransport,
+		},
+		{
+			name:    "DNS pinning",
+			cfg:     &ClientConfiguration{HTTPClient: custom, DNSPinning: &DNSPinning{}},
+			wantErr: ErrUnsupportedTransport,
+		},
+		{
+			name:    "static hosts on a base transport",
+			cfg:     &ClientConfiguration{BaseTransport: base, StaticHosts: map[string]string{"example.com": "127.0.0.1"}},
+			wantErr: ErrUnsupportedTransport,
+		},
+		{
+			name:    "header limits on a base transport",
+			cfg:     &ClientConfiguration{BaseTransport: base, MaxResponseHeaderBytes: 1 << 10},
+			wantErr: ErrUnsupportedTransport,
+		},
+		{
+			name:    "TLS settings on a base transport",
+			cfg:     &ClientConfiguration{BaseTransport: base, TLS: TLSConfiguration{InsecureSkipVerify: true}},
+			wantErr: ErrUnsupportedTransport,
+		},
+		{
+			name: "static hosts on an *http.Transport base transport",
+			cfg:  &ClientConfiguration{BaseTransport: &http.Transport{}, StaticHosts: map[string]string{"example.com": "127.0.0.1"}},
+		},
+		{
+			name: "SSRF protection with an *http.Transport",
+			cfg:  &ClientConfiguration{SSRFProtection: &SSRFProtection{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewClient(tt.cfg); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClientLeavesCallerTransport(t *testing.T) {
+	transport := &http.Transport{}
+	httpClient := &http.Client{Transport: transport}
+
+	for range 2 {
+		client, err := NewClient(&ClientConfiguration{
+			HTTPClient:             httpClient,
+			SSRFProtection:         &SSRFProtection{},
+			MaxResponseHeaderBytes: 1 << 10,
+			AllowedSchemes:         []string{"https"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if client.HTTPClient == httpClient || client.HTTPClient.Transport == transport {
+			t.Fatal("client uses the caller's HTTP client or transport")
+		}
+	}
+
+	if transport.DialContext != nil || transport.MaxResponseHeaderBytes != 0 {
+		t.Fatal("caller's transport was modified")
+	}
+
+	if httpClient.CheckRedirect != nil {
+		t.Fatal("caller's HTTP client was modified")
+	}
+}
+
+func TestNewClientLeavesDefaultTransport(t *testing.T) {
+	transport, _ := http.DefaultTransport.(*http.Transport)
+
+	dial := reflect.ValueOf(transport.DialContext).Pointer()
+
+	if _, err := NewClient(&ClientConfiguration{
+		HTTPClient:  &http.Client{},
+		StaticHosts: map[string]string{"example.com": "127.0.0.1"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if reflect.ValueOf(transport.DialContext).Pointer() != dial {
+		t.Fatal("http.DefaultTransport dialer was replaced")
+	}
+}
+
+func TestDoReturnsLastRetriedResponse(t *testing.T) {
+	tests := []struct {
+		name         string
+		retries      int
+		statuses     []int
+		wantStatus   int
+		wantRequests int32
+	}{
+		{
+			name:         "no retries",
+			statuses:     []int{http.StatusInternalServerError},
+			wantStatus:   http.StatusInternalServerError,
+			wantRequests: 1,
+		},
+		{
+			name:         "retries exhausted",
+			retries:      2,
+			statuses:     []int{http.StatusServiceUnavailable},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "retried on status",
+			retries:      2,
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				sent := int(requests.Add(1))
+
+				status := tt.statuses[min(sent, len(tt.statuses))-1]
+
+				w.WriteHeader(status)
+
+				_, _ = io.WriteString(w, http.StatusText(status))
+			}))
+
+			defer server.Close()
+
+			client, err := NewClient(&ClientConfiguration{
+				Timeout:      5 * time.Second,
+				Retries:      tt.retries,
+				RetryWaitMin: time.Millisecond,
+				RetryWaitMax: time.Millisecond,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := client.GET(server.URL).Send()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			defer res.Body.Close()
+
+			body, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.StatusCode != tt.wantStatus || string(body) != http.StatusText(tt.wantStatus) {
+				t.Fatalf("got %d %q, want %d %q", res.StatusCode, body, tt.wantStatus, http.StatusText(tt.wantStatus))
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Fatalf("got %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDoHonorsRetryAfter(t *testing.T) {
+	const backoff = 50 * time.Millisecond
+
+	tests := []struct {
+		name             string
+		status           int
+		retryAfter       func() string
+		retryAfterMax    time.Duration
+		ignoreRetryAfter bool
+		wantMin          time.Duration
+		wantMax          time.Duration
+	}{
+		{
+			name:       "delta-seconds",
+			status:     http.StatusTooManyRequests,
+			retryAfter: func() string { return "1" },
+			wantMin:    time.Second,
+			wantMax:    1500 * time.Millisecond,
+		},
+		{
+			name:       "HTTP-date",
+			status:     http.StatusServiceUnavailable,
+			retryAfter: func() string { return time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat) },
+			wantMin:    time.Second,
+			wantMax:    2500 * time.Millisecond,
+		},
+		{
+			name:       "past HTTP-date",
+			status:     http.StatusServiceUnavailable,
+			retryAfter: func() string { return time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat) },
+			wantMin:    backoff,
+			wantMax:    500 * time.Millisecond,
+		},
+		{
+			name:          "capped",
+			status:        http.StatusTooManyRequests,
+			retryAfter:    func() string { return "120" },
+			retryAfterMax: 200 * time.Millisecond,
+			wantMin:       200 * time.Millisecond,
+			wantMax:       700 * time.Millisecond,
+		},
+		{
+			name:             "ignored",
+			status:           http.StatusTooManyRequests,
+			retryAfter:       func() string { return "120" },
+			ignoreRetryAfter: true,
+			wantMin:          backoff,
+			wantMax:          500 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			var first, second time.Time
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if requests.Add(1) == 1 {
+					first = time.Now()
+
+					w.Header().Set(headers.RetryAfter.String(), tt.retryAfter())
+					w.WriteHeader(tt.status)
+
+					return
+				}
+
+				second = time.Now()
+
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			defer server.Close()
+
+			client, err := NewClient(&ClientConfiguration{
+				Timeout:          5 * time.Second,
+				Retries:          1,
+				RetryWaitMin:     backoff,
+				RetryWaitMax:     backoff,
+				RetryAfterMax:    tt.retryAfterMax,
+				IgnoreRetryAfter: tt.ignoreRetryAfter,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := client.GET(server.URL).Send()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			res.Body.Close()
+
+			if res.StatusCode != http.StatusNoContent {
+				t.Fatalf("got status %d, want %d", res.StatusCode, http.StatusNoContent)
+			}
+
+			if wait := second.Sub(first); wait < tt.wantMin || wait > tt.wantMax {
+				t.Fatalf("waited %v, want between %v and %v", wait, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestDoHonorsCallerContext(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int // Status answered to every request, none to hang until cancelled.
+		cancelAfter  time.Duration
+		deadline     time.Duration
+		wantErr      error
+		wantRequests int32
+	}{
+		{
+			name:         "cancelled in flight",
+			cancelAfter:  100 * time.Millisecond,
+			wantErr:      context.Canceled,
+			wantRequests: 1,
+		},
+		{
+			name:         "cancelled between retries",
+			status:       http.StatusServiceUnavailable,
+			cancelAfter:  100 * time.Millisecond,
+			wantErr:      context.Canceled,
+			wantRequests: 1,
+		},
+		{
+			name:         "deadline shorter than the timeout",
+			deadline:     100 * time.Millisecond,
+			wantErr:      context.DeadlineExceeded,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+
+				if tt.status == 0 {
+					select {
+					case <-r.Context().Done():
+					case <-time.After(5 * time.Second):
+					}
+
+					return
+				}
+
+				w.WriteHeader(tt.status)
+			}))
+
+			defer server.Close()
+
+			client, err := NewClient(&ClientConfiguration{
+				Timeout:      5 * time.Second,
+				Retries:      3,
+				RetryWaitMin: time.Second,
+				RetryWaitMax: time.Second,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+
+			defer cancel()
+
+			if tt.deadline > 0 {
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+
+				defer cancel()
+			}
+
+			if tt.cancelAfter > 0 {
+				time.AfterFunc(tt.cancelAfter, cancel)
+			}
+
+			start := time.Now()
+
+			res, err := client.GET(server.URL).Context(ctx).Send()
+			if err == nil {
+				res.Body.Close()
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Fatalf("returned after %v, want the context to abort the request", elapsed)
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Fatalf("got %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
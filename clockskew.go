@@ -0,0 +1,114 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// clockSkewSmoothing is the weight given to a new observation when updating the smoothed
+// clock skew of a host (exponentially weighted moving average).
+const clockSkewSmoothing = 0.2
+
+// clockSkewTracker measures, per host, the difference between the server clock reported
+// through the Date header and the local clock.
+type clockSkewTracker struct {
+	mutex sync.RWMutex
+	skews map[string]time.Duration
+}
+
+// observe updates the smoothed skew of a host from the Date header of a response.
+// The local reference time is the midpoint between sending the request and receiving
+// the response, which halves the error introduced by network latency.
+//
+// Parameters:
+//   - host: The host the response was received from.
+//   - header: The response header.
+//   - sent: The time the request was sent.
+//   - received: The time the response was received.
+//
+// Returns: None.
+func (t *clockSkewTracker) observe(host string, header http.Header, sent, received time.Time) {
+	value := header.Get(headers.Date.String())
+
+	if value == "" {
+		return
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return
+	}
+
+	local := sent.Add(received.Sub(sent) / 2)
+
+	skew := date.Sub(local)
+
+	t.mutex.Lock()
+
+	defer t.mutex.Unlock()
+
+	previous, ok := t.skews[host]
+	if !ok {
+		t.skews[host] = skew
+
+		return
+	}
+
+	t.skews[host] = previous + time.Duration(clockSkewSmoothing*float64(skew-previous))
+}
+
+// skew returns the smoothed skew of a host.
+//
+// Parameters:
+//   - host: The host.
+//
+// Returns:
+//   - skew: The smoothed skew, positive if the server clock is ahead of the local clock.
+//   - ok: Whether the host has been observed.
+func (t *clockSkewTracker) skew(host string) (skew time.Duration, ok bool) {
+	t.mutex.RLock()
+
+	defer t.mutex.RUnlock()
+
+	skew, ok = t.skews[host]
+
+	return
+}
+
+// newClockSkewTracker creates an empty clockSkewTracker.
+//
+// Parameters: None.
+//
+// Returns:
+//   - tracker: The new tracker.
+func newClockSkewTracker() (tracker *clockSkewTracker) {
+	tracker = &clockSkewTracker{
+		skews: make(map[string]time.Duration),
+	}
+
+	return
+}
+
+// ClockSkew returns the measured difference between the clock of the given host and the
+// local clock, smoothed over the responses received from it. Adding the skew to the local
+// time approximates the server time, which is what request signing schemes relying on
+// timestamps need. Skew is only measured if ClientConfiguration.TrackClockSkew is set.
+//
+// Parameters:
+//   - host: The host, as found in the request URL (including the port, if any).
+//
+// Returns:
+//   - skew: The smoothed skew, positive if the server clock is ahead of the local clock.
+//   - ok: Whether a skew has been measured for the host.
+func (c *Client) ClockSkew(host string) (skew time.Duration, ok bool) {
+	if c.clockSkew == nil {
+		return
+	}
+
+	skew, ok = c.clockSkew.skew(host)
+
+	return
+}
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// ErrDecompressionBomb is returned when reading a compressed response body that expands past
+// ClientConfiguration.MaxDecompressedBytes or MaxDecompressionRatio.
+var ErrDecompressionBomb = errors.New("decompression bomb: response body expands past limits")
+
+// decompressionRatioFloor is the decompressed size below which MaxDecompressionRatio is not
+// enforced, so that small, highly repetitive bodies are not mistaken for bombs.
+const decompressionRatioFloor = 1 << 20
+
+// countingReader is an io.Reader counting the bytes read from it.
+type countingReader struct {
+	reader io.Reader
+	read   int64
+}
+
+// Read reads from the underlying reader and counts the bytes read.
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+
+	r.read += int64(n)
+
+	return
+}
+
+// decompressedBody decodes a gzip response body, failing with ErrDecompressionBomb once the
+// decoded size or the ratio between the decoded and encoded sizes exceeds its limits.
+type decompressedBody struct {
+	body       io.ReadCloser
+	compressed *countingReader
+	reader     *gzip.Reader
+	maxBytes   int64
+	maxRatio   float64
+	read       int64
+	err        error
+}
+
+// Read reads decoded bytes from the body.
+func (b *decompressedBody) Read(p []byte) (n int, err error) {
+	if b.err != nil {
+		err = b.err
+
+		return
+	}
+
+	// The gzip reader reads the gzip header on creation, so it is only created on first read.
+	if b.reader == nil {
+		if b.reader, b.err = gzip.NewReader(b.compressed); b.err != nil {
+			err = b.err
+
+			return
+		}
+	}
+
+	n, err = b.reader.Read(p)
+
+	b.read += int64(n)
+
+	if b.maxBytes > 0 && b.read > b.maxBytes {
+		b.err = fmt.Errorf("%w: more than %d bytes", ErrDecompressionBomb, b.maxBytes)
+	} else if b.maxRatio > 0 && b.read > decompressionRatioFloor && float64(b.read) > b.maxRatio*float64(b.compressed.read) {
+		b.err = fmt.Errorf("%w: ratio above %.0f", ErrDecompressionBomb, b.maxRatio)
+	}
+
+	if b.err != nil {
+		n, err = 0, b.err
+	}
+
+	return
+}
+
+// Close closes the underlying body.
+func (b *decompressedBody) Close() (err error) {
+	err = b.body.Close()
+
+	return
+}
+
+// claimDecompression takes over the transparent gzip decoding of the transport for a request
+// when decompression limits, response dumps or body fingerprints are configured: attempts of
+// the request advertise gzip themselves, which stops the transport from decoding the
+// response, so that responses are dumped and fingerprinted as received and
+// decompressResponse can decode them with limits. The claim is recorded on the request, not
+// in its header, which may be shared with other requests (e.g., by a RequestBuilder).
+//
+// Parameters:
+//   - req: The request to update.
+//
+// Returns: None.
+func (c *Client) claimDecompression(req *Request) {
+	req.decompress = false
+
+	if c.cfg.MaxDecompressedBytes <= 0 && c.cfg.MaxDecompressionRatio <= 0 && c.cfg.DumpResponse == nil && !c.cfg.FingerprintBodies {
+		return
+	}
+
+	// The conditions under which the transport would advertise gzip itself.
+	if req.Header.Get(headers.AcceptEncoding.String()) != "" || req.acceptEncoding != "" || req.Header.Get(headers.Range.String()) != "" || req.Method == http.MethodHead {
+		return
+	}
+
+	if transport, ok := c.HTTPClient.Transport.(*http.Transport); ok && transport.DisableCompression {
+		return
+	}
+
+	req.decompress = true
+}
+
+// decompressResponse decodes a gzip response to a request claimed by claimDecompression,
+// updating the response the way the transport does when decoding it transparently.
+//
+// Parameters:
+//   - res: The response, updated in place.
+//
+// Returns: None.
+func (c *Client) decompressResponse(res *http.Response) {
+	if !strings.EqualFold(res.Header.Get(headers.ContentEncoding.String()), "gzip") {
+		return
+	}
+
+	res.Body = &decompressedBody{
+		body:       res.Body,
+		compressed: &countingReader{reader: res.Body},
+		maxBytes:   c.cfg.MaxDecompressedBytes,
+		maxRatio:   c.cfg.MaxDecompressionRatio,
+	}
+
+	res.Header.Del(headers.ContentEncoding.String())
+	res.Header.Del(headers.ContentLength.String())
+
+	res.ContentLength = -1
+	res.Uncompressed = true
+}
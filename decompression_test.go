@@ -0,0 +1,60 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClaimDecompressionOnReusedBuilder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = io.WriteString(w, "hello")
+
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+
+		writer := gzip.NewWriter(w)
+
+		_, _ = io.WriteString(writer, "hello")
+
+		_ = writer.Close()
+	}))
+
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfiguration{
+		Timeout:              5 * time.Second,
+		MaxDecompressedBytes: 1 << 20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := client.GET(server.URL)
+
+	for i := range 3 {
+		res, err := builder.Send()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(res.Body)
+
+		res.Body.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(body) != "hello" {
+			t.Fatalf("send %d: got body %q, want %q", i+1, body, "hello")
+		}
+	}
+}
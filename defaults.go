@@ -118,7 +118,7 @@ func HEAD(URL string) *RequestBuilder {
 // }
 
 func POST(URL string) *RequestBuilder {
-	return DefaultClient.HEAD(URL)
+	return DefaultClient.POST(URL)
 }
 
 // func Post(URL, bodyType string, body interface{}) (res *http.Response, err error) {
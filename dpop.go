@@ -0,0 +1,205 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.source.hueristiq.com/http/headers"
+	"go.source.hueristiq.com/http/jwt"
+)
+
+// ErrInvalidDPoPKey is returned by NewDPoPSigner for keys other than P-256 ECDSA keys.
+var ErrInvalidDPoPKey = errors.New("DPoP key must be a P-256 ECDSA key")
+
+// DPoPSigner generates OAuth 2.0 DPoP proofs (RFC 9449) binding requests to a key pair.
+// Set through ClientConfiguration.DPoP, every attempt gets a fresh proof in the DPoP header,
+// bound to the access token of "Authorization: DPoP <token>" headers. Nonces sent by servers
+// through the DPoP-Nonce header are remembered per origin and, when a server rejects a proof
+// with a use_dpop_nonce error, the request is sent again once with the nonce.
+type DPoPSigner struct {
+	key *ecdsa.PrivateKey
+	jwk map[string]interface{}
+
+	mutex  sync.Mutex
+	nonces map[string]string
+}
+
+// Proof generates a DPoP proof for a request.
+//
+// Parameters:
+//   - method: The HTTP method of the request.
+//   - URL: The URL of the request. Its query and fragment are left out of the proof.
+//   - accessToken: The access token sent with the request, empty if none.
+//
+// Returns:
+//   - proof: The DPoP proof JWT.
+//   - err: An error if the proof cannot be signed.
+func (s *DPoPSigner) Proof(method, URL, accessToken string) (proof string, err error) {
+	jti := make([]byte, 16)
+
+	if _, err = rand.Read(jti); err != nil {
+		return
+	}
+
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return
+	}
+
+	htu := url.URL{Scheme: parsed.Scheme, Host: parsed.Host, Path: parsed.Path, RawPath: parsed.RawPath}
+
+	claims := jwt.Claims{
+		"jti": hex.EncodeToString(jti),
+		"htm": method,
+		"htu": htu.String(),
+		"iat": time.Now().Unix(),
+	}
+
+	if nonce := s.nonce(originOf(parsed)); nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	if accessToken != "" {
+		hash := sha256.Sum256([]byte(accessToken))
+
+		claims["ath"] = base64.RawURLEncoding.EncodeToString(hash[:])
+	}
+
+	header := jwt.Header{
+		Algorithm: jwt.ES256,
+		Type:      "dpop+jwt",
+		JWK:       s.jwk,
+	}
+
+	proof, err = jwt.SignWithHeader(header, s.key, claims)
+
+	return
+}
+
+// sign sets the DPoP header of an attempt.
+//
+// Parameters:
+//   - attempt: The request of the attempt.
+//
+// Returns:
+//   - err: An error if the proof cannot be signed.
+func (s *DPoPSigner) sign(attempt *http.Request) (err error) {
+	var accessToken string
+
+	if credentials, errr := headers.ParseAuthorization(attempt.Header.Get(headers.Authorization.String())); errr == nil && strings.EqualFold(credentials.Scheme, "DPoP") {
+		accessToken = credentials.Token68
+	}
+
+	proof, err := s.Proof(attempt.Method, attempt.URL.String(), accessToken)
+	if err != nil {
+		return
+	}
+
+	attempt.Header.Set(headers.DPoP.String(), proof)
+
+	return
+}
+
+// observe remembers the nonce a response carries and reports whether the response rejected
+// the proof for lacking that nonce, in which case the request should be sent again.
+//
+// Parameters:
+//   - attempt: The request of the attempt.
+//   - res: The response.
+//
+// Returns:
+//   - challenged: Whether the request should be sent again with the new nonce.
+func (s *DPoPSigner) observe(attempt *http.Request, res *http.Response) (challenged bool) {
+	nonce := res.Header.Get(headers.DPoPNonce.String())
+
+	if nonce == "" {
+		return
+	}
+
+	origin := originOf(attempt.URL)
+
+	previous := s.nonce(origin)
+
+	s.mutex.Lock()
+
+	s.nonces[origin] = nonce
+
+	s.mutex.Unlock()
+
+	// Resource servers answer 401 with a WWW-Authenticate error, authorization servers 400
+	// with the error in the body (RFC 9449, sections 8 and 9).
+	switch res.StatusCode {
+	case http.StatusUnauthorized:
+		challenged = strings.Contains(res.Header.Get(headers.WWWAuthenticate.String()), "use_dpop_nonce")
+	case http.StatusBadRequest:
+		challenged = true
+	}
+
+	challenged = challenged && nonce != previous
+
+	return
+}
+
+// nonce returns the last nonce received from an origin.
+//
+// Parameters:
+//   - origin: The origin.
+//
+// Returns:
+//   - nonce: The nonce, empty if none was received.
+func (s *DPoPSigner) nonce(origin string) (nonce string) {
+	s.mutex.Lock()
+
+	defer s.mutex.Unlock()
+
+	nonce = s.nonces[origin]
+
+	return
+}
+
+// originOf returns the scheme and host of a URL.
+//
+// Parameters:
+//   - u: The URL.
+//
+// Returns:
+//   - origin: The origin (e.g., "https://example.com:8443").
+func originOf(u *url.URL) (origin string) {
+	origin = strings.ToLower(u.Scheme + "://" + u.Host)
+
+	return
+}
+
+// NewDPoPSigner creates a DPoPSigner proving possession of the given key.
+//
+// Parameters:
+//   - key: The P-256 ECDSA key access tokens are bound to.
+//
+// Returns:
+//   - signer: The new signer.
+//   - err: ErrInvalidDPoPKey if the key is not a P-256 key.
+func NewDPoPSigner(key *ecdsa.PrivateKey) (signer *DPoPSigner, err error) {
+	if key == nil || key.Curve != elliptic.P256() {
+		err = ErrInvalidDPoPKey
+
+		return
+	}
+
+	signer = &DPoPSigner{
+		key:    key,
+		jwk:    jwt.PublicJWK(&key.PublicKey),
+		nonces: make(map[string]string),
+	}
+
+	return
+}
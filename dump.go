@@ -0,0 +1,136 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httputil"
+)
+
+// DumpHook defines a function type called with the raw request or response of every attempt,
+// e.g. to store traffic as it is exchanged or to detect in-path modification of responses by
+// comparing them against known-good baselines. Dumps are the HTTP/1.1 serialization of the
+// messages, as framed by the transport (e.g., User-Agent, Content-Length and Transfer-Encoding
+// included), followed by up to ClientConfiguration.MaxDumpBodyBytes of the body. Response
+// bodies are dumped before any content decoding: the gzip decoding the transport would do
+// transparently is taken over by the client and done after the dump. Hooks of hedged attempts
+// may be called concurrently.
+//
+// Parameters:
+//   - req: The request.
+//   - dump: The raw message, owned by the hook.
+//
+// Returns: None.
+type DumpHook func(req *Request, dump []byte)
+
+// prefixedBody is a body whose first bytes were read ahead for a dump.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// dumpRequest calls the request dump hook with the request of an attempt.
+//
+// Parameters:
+//   - req: The request.
+//   - attempt: The request of the attempt, whose body is replaced if part of it is dumped.
+//
+// Returns:
+//   - err: An error if the request cannot be serialized or its body cannot be read.
+func (c *Client) dumpRequest(req *Request, attempt *http.Request) (err error) {
+	if c.cfg.DumpRequest == nil {
+		return
+	}
+
+	dump, err := httputil.DumpRequestOut(attempt, false)
+	if err != nil {
+		return
+	}
+
+	if attempt.Body != nil && attempt.Body != http.NoBody {
+		var body []byte
+
+		body, attempt.Body, err = readBodyPrefix(attempt.Body, c.cfg.MaxDumpBodyBytes)
+		if err != nil {
+			return
+		}
+
+		dump = append(dump, body...)
+	}
+
+	c.cfg.DumpRequest(req, dump)
+
+	return
+}
+
+// dumpResponse calls the response dump hook with the response of an attempt.
+//
+// Parameters:
+//   - req: The request.
+//   - res: The response of the attempt, nil if it failed.
+//   - err: The error the attempt failed with.
+//
+// Returns:
+//   - dumpedRes: The response, its body replaced if part of it is dumped, nil if it cannot be read.
+//   - dumpedErr: The error to report.
+func (c *Client) dumpResponse(req *Request, res *http.Response, err error) (dumpedRes *http.Response, dumpedErr error) {
+	dumpedRes, dumpedErr = res, err
+
+	if c.cfg.DumpResponse == nil || err != nil || res == nil {
+		return
+	}
+
+	dump, dumpedErr := httputil.DumpResponse(res, false)
+	if dumpedErr != nil {
+		res.Body.Close()
+
+		dumpedRes = nil
+
+		return
+	}
+
+	var body []byte
+
+	body, res.Body, dumpedErr = readBodyPrefix(res.Body, c.cfg.MaxDumpBodyBytes)
+	if dumpedErr != nil {
+		res.Body.Close()
+
+		dumpedRes = nil
+
+		return
+	}
+
+	c.cfg.DumpResponse(req, append(dump, body...))
+
+	return
+}
+
+// readBodyPrefix reads the first bytes of a body, returning a body that still yields them.
+//
+// Parameters:
+//   - body: The body.
+//   - limit: The number of bytes to read.
+//
+// Returns:
+//   - prefix: The bytes read.
+//   - replaced: The body to use in place of the original one.
+//   - err: An error if the body cannot be read.
+func readBodyPrefix(body io.ReadCloser, limit int64) (prefix []byte, replaced io.ReadCloser, err error) {
+	replaced = body
+
+	if limit <= 0 {
+		return
+	}
+
+	prefix, err = io.ReadAll(io.LimitReader(body, limit))
+	if err != nil {
+		return
+	}
+
+	replaced = &prefixedBody{
+		Reader: io.MultiReader(bytes.NewReader(prefix), body),
+		Closer: body,
+	}
+
+	return
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"strings"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// applyAcceptEncoding sets the Accept-Encoding header of the attempts of a request to the
+// content codings configured for its host through ClientConfiguration.AcceptEncodings.
+// Requests that already carry an Accept-Encoding header, or whose host has no override, are
+// left untouched, in which case the transport advertises (and transparently decodes) gzip.
+// The codings are recorded on the request rather than in its header, which may be shared
+// with other requests (e.g., by a RequestBuilder).
+//
+// Note that the transport only decodes responses transparently when it set Accept-Encoding
+// itself: responses to requests with an override are returned as sent by the server.
+//
+// Parameters:
+//   - req: The request to update.
+//
+// Returns: None.
+func (c *Client) applyAcceptEncoding(req *Request) {
+	req.acceptEncoding = ""
+
+	if len(c.cfg.AcceptEncodings) == 0 || req.Header.Get(headers.AcceptEncoding.String()) != "" {
+		return
+	}
+
+	encodings, ok := c.cfg.AcceptEncodings[strings.ToLower(req.URL.Hostname())]
+	if !ok {
+		return
+	}
+
+	req.acceptEncoding = "identity"
+
+	if len(encodings) > 0 {
+		req.acceptEncoding = strings.Join(encodings, ", ")
+	}
+}
+
+// responseContentEncoding returns the content coding a response was sent with, including
+// gzip responses the transport already decoded, or "identity" if the response was not encoded.
+//
+// Parameters:
+//   - uncompressed: Whether the transport decoded the response (http.Response.Uncompressed).
+//   - value: The Content-Encoding header value of the response.
+//
+// Returns:
+//   - encoding: The content coding(s), lowercased.
+func responseContentEncoding(uncompressed bool, value string) (encoding string) {
+	switch {
+	case uncompressed:
+		encoding = "gzip"
+	case value == "":
+		encoding = "identity"
+	default:
+		encoding = strings.ToLower(strings.Join(headers.SplitListField(value), ", "))
+	}
+
+	return
+}
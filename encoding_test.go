@@ -0,0 +1,48 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAcceptEncodingsLeaveBuilderHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Header.Get("Accept-Encoding"))
+	}))
+
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfiguration{
+		Timeout:         5 * time.Second,
+		AcceptEncodings: map[string][]string{"127.0.0.1": {"br"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := client.GET(server.URL)
+
+	res, err := builder.Send()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(res.Body)
+
+	res.Body.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != "br" {
+		t.Fatalf("got Accept-Encoding %q, want %q", body, "br")
+	}
+
+	if value := builder.header.Get("Accept-Encoding"); value != "" {
+		t.Fatalf("builder header got Accept-Encoding %q, want none", value)
+	}
+}
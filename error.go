@@ -1,6 +1,14 @@
 package http
 
-import "net/http"
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
 
 // ErrorHandler defines a function type that handles HTTP response errors if retries are expired,
 // containing the last status from the http library.
@@ -14,3 +22,134 @@ import "net/http"
 //   - req: The processed or retried HTTP response. This may be nil if the function decides not to retry.
 //   - herr: An error returned by the error handler, signaling either a failure in retrying or a terminal error condition.
 type ErrorHandler func(res *http.Response, err error, tries int) (req *http.Response, herr error)
+
+// sensitiveNames is the alternation of the credential-like field names whose values are redacted.
+const sensitiveNames = `password|passwd|secret|client_secret|token|access_token|refresh_token|id_token|api_?key`
+
+var (
+	// sensitiveBodyValueRegex matches the values of credential-like fields in JSON and form
+	// encoded bodies, redacted from the body snippets of RequestError, and in URL queries.
+	sensitiveBodyValueRegex = regexp.MustCompile(`(?i)("?(?:` + sensitiveNames + `)"?\s*[:=]\s*"?)([^"&\s,}]+)`)
+	// sensitiveNameRegex matches the names of the fields sensitiveBodyValueRegex redacts.
+	sensitiveNameRegex = regexp.MustCompile(`(?i)(?:` + sensitiveNames + `)$`)
+)
+
+// RequestError is the error Client.Do returns once it gives up on a request, annotated so that
+// the failure can be diagnosed from logs alone. It wraps the error of the last attempt.
+type RequestError struct {
+	Method      string        // Method of the request.
+	URL         string        // Final URL of the request (e.g., after redirects), its password and query credentials redacted.
+	Attempts    int           // Number of attempts made.
+	Elapsed     time.Duration // Time spent on the request, waits between retries included.
+	StatusCode  int           // Status of the last response received, zero if none.
+	BodySnippet string        // Start of the body of the last response received (see ClientConfiguration.MaxErrorBodyBytes), credentials redacted.
+	Err         error         // Error of the last attempt.
+}
+
+// Error returns the annotated error message.
+//
+// Parameters: None.
+//
+// Returns:
+//   - message: The error message.
+func (e *RequestError) Error() (message string) {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%s %s giving up after %d attempts in %s", e.Method, e.URL, e.Attempts, e.Elapsed.Round(time.Millisecond))
+
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&builder, " (last status %d", e.StatusCode)
+
+		if e.BodySnippet != "" {
+			fmt.Fprintf(&builder, ", body %q", e.BodySnippet)
+		}
+
+		builder.WriteString(")")
+	}
+
+	fmt.Fprintf(&builder, ": %v", e.Err)
+
+	message = builder.String()
+
+	return
+}
+
+// Unwrap returns the error of the last attempt.
+//
+// Parameters: None.
+//
+// Returns:
+//   - err: The error of the last attempt.
+func (e *RequestError) Unwrap() (err error) {
+	err = e.Err
+
+	return
+}
+
+// errorBodySnippet reads the start of a response body for a RequestError, truncated to
+// ClientConfiguration.MaxErrorBodyBytes on a character boundary and with credentials redacted.
+//
+// Parameters:
+//   - res: The response, whose body is partially consumed.
+//
+// Returns:
+//   - snippet: The snippet, empty if disabled or the body cannot be read.
+func (c *Client) errorBodySnippet(res *http.Response) (snippet string) {
+	if c.cfg.MaxErrorBodyBytes <= 0 || res == nil || res.Body == nil {
+		return
+	}
+
+	content, err := io.ReadAll(io.LimitReader(res.Body, c.cfg.MaxErrorBodyBytes+1))
+	if err != nil {
+		return
+	}
+
+	truncated := int64(len(content)) > c.cfg.MaxErrorBodyBytes
+
+	if truncated {
+		content = content[:c.cfg.MaxErrorBodyBytes]
+	}
+
+	snippet = strings.ToValidUTF8(string(content), "")
+	snippet = sensitiveBodyValueRegex.ReplaceAllString(snippet, "${1}"+redactedValue)
+
+	if truncated {
+		snippet += "..."
+	}
+
+	return
+}
+
+// recordFailedResponse annotates a RequestError with a response that failed the request.
+//
+// Parameters:
+//   - failure: The error to annotate.
+//   - res: The response, whose body is partially consumed.
+//
+// Returns: None.
+func (c *Client) recordFailedResponse(failure *RequestError, res *http.Response) {
+	failure.StatusCode = res.StatusCode
+	failure.BodySnippet = c.errorBodySnippet(res)
+
+	if res.Request != nil && res.Request.URL != nil {
+		failure.URL = redactedURL(res.Request.URL)
+	}
+}
+
+// redactedURL returns a URL with its password and the values of credential-like query
+// parameters (e.g., api_key or access_token) redacted.
+//
+// Parameters:
+//   - target: The URL to redact.
+//
+// Returns:
+//   - redacted: The redacted URL.
+func redactedURL(target *url.URL) (redacted string) {
+	clone := *target
+
+	clone.RawQuery = sensitiveBodyValueRegex.ReplaceAllString(clone.RawQuery, "${1}"+redactedValue)
+
+	redacted = clone.Redacted()
+
+	return
+}
@@ -0,0 +1,51 @@
+package http
+
+import "strings"
+
+// FallbackPolicy defines when the client falls back to, or starts with, its HTTP/2 client.
+type FallbackPolicy int
+
+const (
+	// FallbackOnProtocolError sends requests with the HTTP/1.x client and retries them with the
+	// HTTP/2 client when the server answers an HTTP/1.x request with an HTTP/2 response.
+	// This is the default policy.
+	FallbackOnProtocolError FallbackPolicy = iota
+	// FallbackNever sends requests with the HTTP/1.x client only.
+	FallbackNever
+	// FallbackHTTP2First sends requests with the HTTP/2 client, which still negotiates
+	// HTTP/1.x with servers that do not offer HTTP/2.
+	FallbackHTTP2First
+)
+
+// String returns the name of the policy.
+func (p FallbackPolicy) String() (policy string) {
+	switch p {
+	case FallbackOnProtocolError:
+		policy = "on-protocol-error"
+	case FallbackNever:
+		policy = "never"
+	case FallbackHTTP2First:
+		policy = "always-h2-first"
+	default:
+		policy = "unknown"
+	}
+
+	return
+}
+
+// malformedHTTP2VersionError is the message net/http reports when an HTTP/1.x transport
+// receives an HTTP/2 response. The error isn't typed, so it is matched on its string.
+const malformedHTTP2VersionError = "net/http: HTTP/1.x transport connection broken: malformed HTTP version \"HTTP/2\""
+
+// isHTTP2ProtocolError reports whether err shows that the server only speaks HTTP/2.
+//
+// Parameters:
+//   - err: The error returned by the HTTP/1.x client.
+//
+// Returns:
+//   - is: Whether the request should be retried with the HTTP/2 client.
+func isHTTP2ProtocolError(err error) (is bool) {
+	is = err != nil && strings.Contains(err.Error(), malformedHTTP2VersionError)
+
+	return
+}
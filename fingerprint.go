@@ -0,0 +1,256 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// fingerprintContextKey is the context key under which Client.Do stores the fingerprinter of a
+// response body on the request of the response, so that ResponseFingerprint can find it.
+const fingerprintContextKey ContextOverride = "body-fingerprint"
+
+// BodyFingerprint holds the hashes of a response body, for cheap change detection and
+// clustering of identical pages. Hashes are hex-encoded SHA-256 digests.
+type BodyFingerprint struct {
+	Raw         string // Hash of the body as received, before content decoding.
+	RawSize     int64  // Size of the body as received.
+	Decoded     string // Hash of the body after the gzip decoding done by the client, Raw if it did none.
+	DecodedSize int64  // Size of the body after decoding.
+	Normalized  string // Hash of the decoded HTML body with whitespace runs collapsed and trimmed, empty for other bodies or if disabled.
+}
+
+// bodyFingerprinter hashes a response body as it is read.
+type bodyFingerprinter struct {
+	raw        hash.Hash
+	decoded    hash.Hash
+	normalized hash.Hash
+
+	rawSize     int64
+	decodedSize int64
+
+	// Whitespace is written to the normalized hash lazily, once followed by other bytes.
+	pendingSpace bool
+	started      bool
+
+	mutex       sync.Mutex
+	fingerprint *BodyFingerprint
+}
+
+// writeRaw hashes bytes of the body as received.
+//
+// Parameters:
+//   - p: The bytes.
+//
+// Returns: None.
+func (f *bodyFingerprinter) writeRaw(p []byte) {
+	f.raw.Write(p)
+
+	f.rawSize += int64(len(p))
+}
+
+// writeDecoded hashes bytes of the decoded body.
+//
+// Parameters:
+//   - p: The bytes.
+//
+// Returns: None.
+func (f *bodyFingerprinter) writeDecoded(p []byte) {
+	f.decoded.Write(p)
+
+	f.decodedSize += int64(len(p))
+
+	if f.normalized == nil {
+		return
+	}
+
+	normalized := make([]byte, 0, len(p))
+
+	for _, b := range p {
+		switch b {
+		case ' ', '\t', '\n', '\r', '\f':
+			f.pendingSpace = f.started
+
+			continue
+		}
+
+		if f.pendingSpace {
+			normalized = append(normalized, ' ')
+		}
+
+		normalized = append(normalized, b)
+
+		f.pendingSpace, f.started = false, true
+	}
+
+	f.normalized.Write(normalized)
+}
+
+// writeBoth hashes bytes of a body that is not decoded, raw and decoded at once.
+//
+// Parameters:
+//   - p: The bytes.
+//
+// Returns: None.
+func (f *bodyFingerprinter) writeBoth(p []byte) {
+	f.writeRaw(p)
+	f.writeDecoded(p)
+}
+
+// finish computes the fingerprint once the decoded body is read to its end.
+//
+// Parameters: None.
+//
+// Returns: None.
+func (f *bodyFingerprinter) finish() {
+	fingerprint := &BodyFingerprint{
+		Raw:         hex.EncodeToString(f.raw.Sum(nil)),
+		RawSize:     f.rawSize,
+		Decoded:     hex.EncodeToString(f.decoded.Sum(nil)),
+		DecodedSize: f.decodedSize,
+	}
+
+	if f.normalized != nil {
+		fingerprint.Normalized = hex.EncodeToString(f.normalized.Sum(nil))
+	}
+
+	f.mutex.Lock()
+
+	f.fingerprint = fingerprint
+
+	f.mutex.Unlock()
+}
+
+// fingerprintedBody is a response body hashed as it is read.
+type fingerprintedBody struct {
+	body   io.ReadCloser
+	write  func(p []byte)
+	finish func()
+}
+
+// Read reads from the body and hashes the bytes read.
+func (b *fingerprintedBody) Read(p []byte) (n int, err error) {
+	n, err = b.body.Read(p)
+
+	b.write(p[:n])
+
+	if err == io.EOF && b.finish != nil {
+		b.finish()
+
+		b.finish = nil
+	}
+
+	return
+}
+
+// Close closes the body.
+func (b *fingerprintedBody) Close() (err error) {
+	err = b.body.Close()
+
+	return
+}
+
+// startFingerprint starts hashing the body of a response as received, before the client
+// decodes it, when ClientConfiguration.FingerprintBodies is set.
+//
+// Parameters:
+//   - res: The response, whose body is replaced.
+//
+// Returns:
+//   - fingerprinter: The fingerprinter to pass to finishFingerprint, nil if disabled.
+//   - raw: The body hashing the raw bytes.
+func (c *Client) startFingerprint(res *http.Response) (fingerprinter *bodyFingerprinter, raw *fingerprintedBody) {
+	if !c.cfg.FingerprintBodies || res.Request == nil {
+		return
+	}
+
+	fingerprinter = &bodyFingerprinter{
+		raw:     sha256.New(),
+		decoded: sha256.New(),
+	}
+
+	if c.cfg.FingerprintHTMLWhitespace {
+		mediaType, _, _ := mime.ParseMediaType(res.Header.Get(headers.ContentType.String()))
+
+		if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+			fingerprinter.normalized = sha256.New()
+		}
+	}
+
+	raw = &fingerprintedBody{
+		body:  res.Body,
+		write: fingerprinter.writeRaw,
+	}
+
+	res.Body = raw
+
+	res.Request = res.Request.WithContext(context.WithValue(res.Request.Context(), fingerprintContextKey, fingerprinter))
+
+	return
+}
+
+// finishFingerprint starts hashing the decoded body of a response, once the client decoded it.
+//
+// Parameters:
+//   - res: The response, whose body is replaced if it was decoded.
+//   - fingerprinter: The fingerprinter of startFingerprint, nil if disabled.
+//   - raw: The body hashing the raw bytes.
+//
+// Returns: None.
+func finishFingerprint(res *http.Response, fingerprinter *bodyFingerprinter, raw *fingerprintedBody) {
+	if fingerprinter == nil {
+		return
+	}
+
+	// A body that was not decoded is hashed once for both.
+	if res.Body == io.ReadCloser(raw) {
+		raw.write, raw.finish = fingerprinter.writeBoth, fingerprinter.finish
+
+		return
+	}
+
+	res.Body = &fingerprintedBody{
+		body:   res.Body,
+		write:  fingerprinter.writeDecoded,
+		finish: fingerprinter.finish,
+	}
+}
+
+// ResponseFingerprint returns the fingerprint of the body of a response, computed if
+// ClientConfiguration.FingerprintBodies is set once the body has been read to its end.
+//
+// Parameters:
+//   - res: The response.
+//
+// Returns:
+//   - fingerprint: The fingerprint.
+//   - ok: Whether the fingerprint is available.
+func ResponseFingerprint(res *http.Response) (fingerprint BodyFingerprint, ok bool) {
+	if res == nil || res.Request == nil {
+		return
+	}
+
+	fingerprinter, _ := res.Request.Context().Value(fingerprintContextKey).(*bodyFingerprinter)
+	if fingerprinter == nil {
+		return
+	}
+
+	fingerprinter.mutex.Lock()
+
+	defer fingerprinter.mutex.Unlock()
+
+	if fingerprinter.fingerprint == nil {
+		return
+	}
+
+	fingerprint, ok = *fingerprinter.fingerprint, true
+
+	return
+}
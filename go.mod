@@ -6,6 +6,5 @@ require (
 	github.com/hueristiq/hqgoutils v0.0.0-20231024005153-bd2c47932440
 	go.source.hueristiq.com/retrier v0.0.0-20250127064203-724abd75d518
 	golang.org/x/net v0.34.0
+	golang.org/x/text v0.21.0
 )
-
-require golang.org/x/text v0.21.0 // indirect
@@ -0,0 +1,359 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultResolutionDelay is the time waited for IPv6 addresses once IPv4 addresses are
+	// resolved, when HappyEyeballs.ResolutionDelay is not set (RFC 8305, section 3).
+	DefaultResolutionDelay = 50 * time.Millisecond
+	// DefaultConnectionAttemptDelay is the time waited before starting the next connection
+	// attempt, when HappyEyeballs.ConnectionAttemptDelay is not set (RFC 8305, section 5).
+	DefaultConnectionAttemptDelay = 250 * time.Millisecond
+)
+
+// HappyEyeballs configures the explicit racing of connection attempts across the addresses of
+// a host, as described by RFC 8305 (Happy Eyeballs Version 2). IPv6 and IPv4 addresses are
+// resolved in parallel and attempted alternately, IPv6 first, each attempt starting once the
+// previous one fails or the attempt delay elapses. The first established connection wins and
+// the other attempts are abandoned, so that hosts stay reachable quickly on broken dual-stack
+// networks.
+type HappyEyeballs struct {
+	ResolutionDelay        time.Duration // Time to wait for IPv6 addresses once IPv4 addresses are resolved, DefaultResolutionDelay if zero.
+	ConnectionAttemptDelay time.Duration // Time to wait before starting the next connection attempt, DefaultConnectionAttemptDelay if zero.
+}
+
+// FamilyDialStats counts the connection attempts made to the addresses of an address family.
+type FamilyDialStats struct {
+	Attempts  int // Connection attempts started.
+	Successes int // Attempts that established the connection used.
+	Failures  int // Attempts that failed.
+	Abandoned int // Attempts abandoned, or connections closed, because another attempt won the race.
+}
+
+// DialStats summarizes the connection attempts raced by HappyEyeballs, per address family.
+type DialStats struct {
+	IPv4 FamilyDialStats
+	IPv6 FamilyDialStats
+}
+
+// dialStatsTracker collects DialStats.
+type dialStatsTracker struct {
+	mutex sync.Mutex
+	stats DialStats
+}
+
+// record updates the statistics of the family of an address.
+//
+// Parameters:
+//   - addr: The address attempted.
+//   - update: The function updating the statistics of its family.
+//
+// Returns: None.
+func (t *dialStatsTracker) record(addr netip.Addr, update func(stats *FamilyDialStats)) {
+	t.mutex.Lock()
+
+	defer t.mutex.Unlock()
+
+	if addr.Unmap().Is4() {
+		update(&t.stats.IPv4)
+	} else {
+		update(&t.stats.IPv6)
+	}
+}
+
+// dialResult is the outcome of a connection attempt.
+type dialResult struct {
+	addr netip.Addr
+	conn net.Conn
+	err  error
+}
+
+// dialContext wraps a dial function so that it resolves the host itself and races connection
+// attempts to the resolved addresses. Addresses refused by ssrf, if set, are left out.
+//
+// Parameters:
+//   - dial: The dial function to wrap.
+//   - ssrf: The SSRF protection to enforce, nil if none.
+//   - stats: The tracker recording the attempts.
+//
+// Returns:
+//   - wrapped: The wrapping dial function.
+func (h *HappyEyeballs) dialContext(dial func(ctx context.Context, network, address string) (net.Conn, error), ssrf *SSRFProtection, stats *dialStatsTracker) (wrapped func(ctx context.Context, network, address string) (net.Conn, error)) {
+	wrapped = func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return
+		}
+
+		var addrs []netip.Addr
+
+		if addr, errr := netip.ParseAddr(host); errr == nil {
+			addrs = []netip.Addr{addr}
+		} else {
+			addrs, err = h.resolve(ctx, network, host)
+			if err != nil {
+				return
+			}
+		}
+
+		if ssrf != nil && !isTrustedDestination(ctx) {
+			permitted := addrs[:0:0]
+
+			for _, addr := range addrs {
+				if ssrf.permits(addr) {
+					permitted = append(permitted, addr)
+				}
+			}
+
+			if len(permitted) == 0 {
+				err = fmt.Errorf("%w: %s", ErrForbiddenDestination, address)
+
+				return
+			}
+
+			addrs = permitted
+		}
+
+		conn, err = h.race(ctx, dial, network, port, addrs, stats)
+
+		return
+	}
+
+	return
+}
+
+// resolve looks up the IPv6 and IPv4 addresses of a host in parallel. Once IPv4 addresses are
+// resolved, IPv6 addresses are waited for up to the resolution delay.
+//
+// Parameters:
+//   - ctx: The dial context.
+//   - network: The dial network, restricting the families looked up for "tcp4" and "tcp6".
+//   - host: The host name.
+//
+// Returns:
+//   - addrs: The addresses, interleaved by family, IPv6 first.
+//   - err: An error if no address could be resolved.
+func (h *HappyEyeballs) resolve(ctx context.Context, network, host string) (addrs []netip.Addr, err error) {
+	type lookup struct {
+		addrs []netip.Addr
+		err   error
+		ipv6  bool
+	}
+
+	families := map[string]bool{"ip6": true, "ip4": false}
+
+	switch network {
+	case "tcp4":
+		delete(families, "ip6")
+	case "tcp6":
+		delete(families, "ip4")
+	}
+
+	lookups := make(chan lookup, len(families))
+
+	for family, ipv6 := range families {
+		go func() {
+			found, errr := net.DefaultResolver.LookupNetIP(ctx, family, host)
+
+			lookups <- lookup{addrs: found, err: errr, ipv6: ipv6}
+		}()
+	}
+
+	resolutionDelay := h.ResolutionDelay
+
+	if resolutionDelay <= 0 {
+		resolutionDelay = DefaultResolutionDelay
+	}
+
+	var ipv4, ipv6 []netip.Addr
+
+	var timeout <-chan time.Time
+
+	for pending := len(families); pending > 0; {
+		select {
+		case <-timeout:
+			pending = 0
+		case result := <-lookups:
+			pending--
+
+			if result.err != nil && err == nil {
+				err = result.err
+			}
+
+			if result.ipv6 {
+				ipv6 = result.addrs
+			} else {
+				ipv4 = result.addrs
+			}
+
+			if !result.ipv6 && len(result.addrs) > 0 {
+				timeout = time.After(resolutionDelay)
+			}
+		}
+	}
+
+	for i := range max(len(ipv4), len(ipv6)) {
+		if i < len(ipv6) {
+			addrs = append(addrs, ipv6[i])
+		}
+
+		if i < len(ipv4) {
+			addrs = append(addrs, ipv4[i])
+		}
+	}
+
+	if len(addrs) > 0 {
+		err = nil
+	}
+
+	return
+}
+
+// race starts connection attempts to the addresses in order, the next one starting when the
+// previous one fails or the connection attempt delay elapses, and returns the first
+// established connection.
+//
+// Parameters:
+//   - ctx: The dial context.
+//   - dial: The dial function.
+//   - network: The dial network.
+//   - port: The port to connect to.
+//   - addrs: The addresses, in order of preference.
+//   - stats: The tracker recording the attempts.
+//
+// Returns:
+//   - conn: The established connection.
+//   - err: The error of the last failed attempt if none succeeded.
+func (h *HappyEyeballs) race(ctx context.Context, dial func(ctx context.Context, network, address string) (net.Conn, error), network, port string, addrs []netip.Addr, stats *dialStatsTracker) (conn net.Conn, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	defer cancel()
+
+	connectionAttemptDelay := h.ConnectionAttemptDelay
+
+	if connectionAttemptDelay <= 0 {
+		connectionAttemptDelay = DefaultConnectionAttemptDelay
+	}
+
+	results := make(chan dialResult, len(addrs))
+
+	next, pending := 0, 0
+
+	start := func() {
+		addr := addrs[next]
+
+		next++
+		pending++
+
+		stats.record(addr, func(stats *FamilyDialStats) { stats.Attempts++ })
+
+		go func() {
+			attempt, errr := dial(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+
+			results <- dialResult{addr: addr, conn: attempt, err: errr}
+		}()
+	}
+
+	start()
+
+	timer := time.NewTimer(connectionAttemptDelay)
+
+	defer timer.Stop()
+
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if next < len(addrs) {
+				start()
+
+				timer.Reset(connectionAttemptDelay)
+			}
+		case result := <-results:
+			pending--
+
+			if result.err == nil {
+				stats.record(result.addr, func(stats *FamilyDialStats) { stats.Successes++ })
+
+				conn = result.conn
+				err = nil
+
+				go abandon(results, pending, stats)
+
+				return
+			}
+
+			stats.record(result.addr, func(stats *FamilyDialStats) { stats.Failures++ })
+
+			err = result.err
+
+			if next < len(addrs) {
+				start()
+
+				timer.Reset(connectionAttemptDelay)
+			}
+		}
+	}
+
+	return
+}
+
+// abandon collects the attempts still pending once the race is won, closing connections
+// established too late.
+//
+// Parameters:
+//   - results: The channel the attempts report to.
+//   - pending: The number of attempts still pending.
+//   - stats: The tracker recording the attempts.
+//
+// Returns: None.
+func abandon(results <-chan dialResult, pending int, stats *dialStatsTracker) {
+	for range pending {
+		result := <-results
+
+		if result.conn != nil {
+			result.conn.Close()
+		}
+
+		stats.record(result.addr, func(stats *FamilyDialStats) { stats.Abandoned++ })
+	}
+}
+
+// newDialStatsTracker creates an empty dialStatsTracker.
+//
+// Parameters: None.
+//
+// Returns:
+//   - tracker: The new tracker.
+func newDialStatsTracker() (tracker *dialStatsTracker) {
+	tracker = &dialStatsTracker{}
+
+	return
+}
+
+// DialStats returns the per address family statistics of the connection attempts raced by
+// ClientConfiguration.HappyEyeballs. Statistics are only collected if it is set.
+//
+// Parameters: None.
+//
+// Returns:
+//   - stats: The statistics.
+func (c *Client) DialStats() (stats DialStats) {
+	if c.dialStats == nil {
+		return
+	}
+
+	c.dialStats.mutex.Lock()
+
+	defer c.dialStats.mutex.Unlock()
+
+	stats = c.dialStats.stats
+
+	return
+}
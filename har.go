@@ -0,0 +1,437 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	hqgoreaderutil "github.com/hueristiq/hqgoutils/reader"
+	"go.source.hueristiq.com/http/headers"
+)
+
+// HARConfiguration defines what a HARRecorder captures.
+type HARConfiguration struct {
+	MaxBodyBytes int64 // Number of bytes of request and response bodies captured. Zero captures no bodies.
+	Redact       bool  // Whether to redact the values of SensitiveHeaders, credential-like query parameters and URL passwords, e.g. before sharing the archive.
+}
+
+// HARRecorder captures the exchanges sent through Client.Do, set through
+// ClientConfiguration.HARRecorder, and exports them as an HTTP Archive (HAR 1.2), e.g. to share
+// the reproduction of a finding. Response bodies are captured as they are read by the caller.
+// Request headers are those of the last request sent, after redirects.
+// Bodies that are not UTF-8 text are recorded base64 encoded, marked by the "encoding" field of
+// the response content and, as HAR has none for request bodies, by a custom "_encoding" field
+// of the request postData.
+type HARRecorder struct {
+	cfg HARConfiguration
+
+	mutex   sync.Mutex
+	entries []*harEntry
+}
+
+type harArchive struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string      `json:"version"`
+	Creator harCreator  `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"`
+
+	started time.Time
+	headers time.Time
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"_encoding,omitempty"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+
+	captured []byte
+}
+
+type harTimings struct {
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// harBody captures a response body into its entry as it is read.
+type harBody struct {
+	io.ReadCloser
+
+	recorder *HARRecorder
+	entry    *harEntry
+}
+
+// Read reads from the body, capturing up to HARConfiguration.MaxBodyBytes.
+func (b *harBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+
+	b.recorder.mutex.Lock()
+
+	defer b.recorder.mutex.Unlock()
+
+	content := &b.entry.Response.Content
+
+	content.Size += int64(n)
+
+	if room := b.recorder.cfg.MaxBodyBytes - int64(len(content.captured)); room > 0 {
+		content.captured = append(content.captured, p[:min(int64(n), room)]...)
+	}
+
+	if err != nil {
+		b.entry.Timings.Receive = milliseconds(time.Since(b.entry.headers))
+		b.entry.Time = milliseconds(time.Since(b.entry.started))
+	}
+
+	return
+}
+
+// start captures a request before it is sent.
+//
+// Parameters:
+//   - req: The request.
+//
+// Returns:
+//   - entry: The entry of the exchange, completed by finish.
+func (r *HARRecorder) start(req *Request) (entry *harEntry) {
+	entry = &harEntry{
+		StartedDateTime: time.Now(),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    max(req.ContentLength, 0),
+		},
+		started: time.Now(),
+	}
+
+	if r.cfg.Redact {
+		entry.Request.URL = redactedURL(req.URL)
+	}
+
+	for key, values := range req.URL.Query() {
+		for _, value := range values {
+			if r.cfg.Redact && sensitiveNameRegex.MatchString(key) {
+				value = redactedValue
+			}
+
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: key, Value: value})
+		}
+	}
+
+	r.captureRequestHeader(entry, req.Request)
+
+	if body, ok := req.Body.(*hqgoreaderutil.ReusableReadCloser); ok && r.cfg.MaxBodyBytes > 0 {
+		if content, err := io.ReadAll(body); err == nil {
+			captured := content[:min(int64(len(content)), r.cfg.MaxBodyBytes)]
+
+			entry.Request.PostData = &harPostData{
+				MimeType: req.Header.Get(headers.ContentType.String()),
+			}
+
+			entry.Request.PostData.Text, entry.Request.PostData.Encoding = harText(captured, len(captured) < len(content))
+		}
+	}
+
+	return
+}
+
+// finish completes the entry of an exchange with its outcome and records it.
+//
+// Parameters:
+//   - entry: The entry returned by start.
+//   - req: The request.
+//   - res: The final response, whose body is wrapped to be captured as it is read.
+//   - err: The error the request failed with.
+//
+// Returns: None.
+func (r *HARRecorder) finish(entry *harEntry, req *Request, res *http.Response, err error) {
+	entry.headers = time.Now()
+
+	entry.Response = harResponse{
+		Cookies:     []harNameValue{},
+		Headers:     []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+
+	if len(req.Metrics.Timings) > 0 {
+		timing := req.Metrics.Timings[len(req.Metrics.Timings)-1]
+
+		entry.Timings = harTimings{
+			DNS:     milliseconds(timing.DNSLookup),
+			Connect: milliseconds(timing.Connect),
+			SSL:     milliseconds(timing.TLSHandshake),
+			Wait:    milliseconds(timing.ServerProcessing),
+		}
+	}
+
+	entry.Time = milliseconds(entry.headers.Sub(entry.started))
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	if res != nil {
+		entry.Response.Status = res.StatusCode
+		entry.Response.StatusText = http.StatusText(res.StatusCode)
+		entry.Response.HTTPVersion = res.Proto
+		entry.Response.Headers = r.nameValues(res.Header)
+		entry.Response.RedirectURL = res.Header.Get(headers.Location.String())
+		entry.Response.Content.MimeType = res.Header.Get(headers.ContentType.String())
+
+		// The request of the response is the last one sent, with the headers added per attempt.
+		if res.Request != nil {
+			r.captureRequestHeader(entry, res.Request)
+		}
+
+		for _, cookie := range res.Cookies() {
+			entry.Response.Cookies = append(entry.Response.Cookies, harNameValue{Name: cookie.Name, Value: r.redact(headers.SetCookie.String(), cookie.Value)})
+		}
+
+		res.Body = &harBody{ReadCloser: res.Body, recorder: r, entry: entry}
+	}
+
+	r.mutex.Lock()
+
+	r.entries = append(r.entries, entry)
+
+	r.mutex.Unlock()
+}
+
+// captureRequestHeader records the headers and cookies of a request in its entry.
+//
+// Parameters:
+//   - entry: The entry of the exchange.
+//   - req: The request, as built or as last sent.
+//
+// Returns: None.
+func (r *HARRecorder) captureRequestHeader(entry *harEntry, req *http.Request) {
+	entry.Request.Headers = r.nameValues(req.Header)
+	entry.Request.Cookies = []harNameValue{}
+
+	for _, cookie := range req.Cookies() {
+		entry.Request.Cookies = append(entry.Request.Cookies, harNameValue{Name: cookie.Name, Value: r.redact(headers.Cookie.String(), cookie.Value)})
+	}
+}
+
+// nameValues converts a header to HAR name/value pairs, redacting sensitive values if configured.
+//
+// Parameters:
+//   - header: The header.
+//
+// Returns:
+//   - pairs: The pairs, sorted by name.
+func (r *HARRecorder) nameValues(header http.Header) (pairs []harNameValue) {
+	pairs = []harNameValue{}
+
+	for _, key := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[key] {
+			pairs = append(pairs, harNameValue{Name: key, Value: r.redact(key, value)})
+		}
+	}
+
+	return
+}
+
+// redact returns the value of a header, redacted if configured and the header is sensitive.
+//
+// Parameters:
+//   - key: The header name.
+//   - value: The header value.
+//
+// Returns:
+//   - redacted: The value to record.
+func (r *HARRecorder) redact(key, value string) (redacted string) {
+	redacted = value
+
+	if r.cfg.Redact && slices.ContainsFunc(SensitiveHeaders, func(sensitive string) bool {
+		return strings.EqualFold(sensitive, key)
+	}) {
+		redacted = redactedValue
+	}
+
+	return
+}
+
+// Export writes the recorded exchanges as HAR 1.2 JSON.
+//
+// Parameters:
+//   - w: The writer to write the archive to.
+//
+// Returns:
+//   - err: An error if the archive cannot be written.
+func (r *HARRecorder) Export(w io.Writer) (err error) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	for _, entry := range r.entries {
+		content := &entry.Response.Content
+
+		content.Text, content.Encoding = harText(content.captured, content.Size > int64(len(content.captured)))
+	}
+
+	archive := harArchive{
+		Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{Name: "go.source.hueristiq.com/http", Version: "1"},
+			Entries: r.entries,
+		},
+	}
+
+	if archive.Log.Entries == nil {
+		archive.Log.Entries = []*harEntry{}
+	}
+
+	encoder := json.NewEncoder(w)
+
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(archive)
+
+	return
+}
+
+// Reset discards the recorded exchanges.
+//
+// Parameters: None.
+//
+// Returns: None.
+func (r *HARRecorder) Reset() {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.entries = nil
+}
+
+// harText converts a captured body to the text of a HAR entry. Bodies that are not valid UTF-8
+// are base64 encoded, as JSON would otherwise replace their bytes with U+FFFD. A text body cut
+// by the capture limit is trimmed to its last complete character rather than encoded.
+//
+// Parameters:
+//   - captured: The captured bytes of the body.
+//   - truncated: Whether the capture limit cut the body.
+//
+// Returns:
+//   - text: The text to record.
+//   - encoding: "base64" if text is base64 encoded, empty otherwise.
+func harText(captured []byte, truncated bool) (text, encoding string) {
+	valid := captured
+
+	if truncated {
+		for i := 1; i <= min(utf8.UTFMax, len(valid)); i++ {
+			if start := len(valid) - i; utf8.RuneStart(valid[start]) {
+				if !utf8.FullRune(valid[start:]) {
+					valid = valid[:start]
+				}
+
+				break
+			}
+		}
+	}
+
+	if utf8.Valid(valid) {
+		text = string(valid)
+
+		return
+	}
+
+	text, encoding = base64.StdEncoding.EncodeToString(captured), "base64"
+
+	return
+}
+
+// milliseconds converts a duration to fractional milliseconds, as used by HAR.
+//
+// Parameters:
+//   - duration: The duration.
+//
+// Returns:
+//   - ms: The duration in milliseconds.
+func milliseconds(duration time.Duration) (ms float64) {
+	ms = float64(duration) / float64(time.Millisecond)
+
+	return
+}
+
+// NewHARRecorder creates an empty HARRecorder.
+//
+// Parameters:
+//   - cfg: What to capture.
+//
+// Returns:
+//   - recorder: The new recorder.
+func NewHARRecorder(cfg HARConfiguration) (recorder *HARRecorder) {
+	recorder = &HARRecorder{
+		cfg: cfg,
+	}
+
+	return
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHARRecorderBodies(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         []byte
+		maxBodyBytes int64
+		wantText     string
+		wantEncoding string
+	}{
+		{
+			name:         "text body",
+			body:         []byte("héllo"),
+			maxBodyBytes: 64,
+			wantText:     "héllo",
+		},
+		{
+			name:         "text body cut inside a character",
+			body:         []byte("héllo"),
+			maxBodyBytes: 2,
+			wantText:     "h",
+		},
+		{
+			name:         "binary body",
+			body:         []byte{0xff, 0x00, 0x89, 'P'},
+			maxBodyBytes: 64,
+			wantText:     "/wCJUA==",
+			wantEncoding: "base64",
+		},
+		{
+			name:         "binary body cut by the capture limit",
+			body:         []byte{0xff, 0x00, 0x89, 'P'},
+			maxBodyBytes: 3,
+			wantText:     "/wCJ",
+			wantEncoding: "base64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/octet-stream")
+
+				_, _ = w.Write(tt.body)
+			}))
+
+			defer server.Close()
+
+			recorder := NewHARRecorder(HARConfiguration{MaxBodyBytes: tt.maxBodyBytes})
+
+			client, err := NewClient(&ClientConfiguration{
+				Timeout:     5 * time.Second,
+				HARRecorder: recorder,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := client.POST(server.URL).Body(bytes.Clone(tt.body)).Send()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _ = io.Copy(io.Discard, res.Body)
+
+			res.Body.Close()
+
+			var buffer bytes.Buffer
+
+			if err = recorder.Export(&buffer); err != nil {
+				t.Fatal(err)
+			}
+
+			var archive harArchive
+
+			if err = json.Unmarshal(buffer.Bytes(), &archive); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(archive.Log.Entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(archive.Log.Entries))
+			}
+
+			entry := archive.Log.Entries[0]
+
+			if content := entry.Response.Content; content.Text != tt.wantText || content.Encoding != tt.wantEncoding {
+				t.Fatalf("got response text %q (encoding %q), want %q (encoding %q)", content.Text, content.Encoding, tt.wantText, tt.wantEncoding)
+			}
+
+			if postData := entry.Request.PostData; postData == nil || postData.Text != tt.wantText || postData.Encoding != tt.wantEncoding {
+				t.Fatalf("got post data %+v, want text %q (encoding %q)", postData, tt.wantText, tt.wantEncoding)
+			}
+		})
+	}
+}
+
+func TestHARRecorderRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	recorder := NewHARRecorder(HARConfiguration{Redact: true})
+
+	client, err := NewClient(&ClientConfiguration{
+		Timeout:     5 * time.Second,
+		HARRecorder: recorder,
+		OnRequest: func(_ *Request, attempt *http.Request) error {
+			attempt.Header.Set("Authorization", "Bearer s3cret")
+			attempt.Header.Set("X-Attempt", "1")
+
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := client.GET(server.URL + "/?api_key=k3y&page=2").Send()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	var buffer bytes.Buffer
+
+	if err = recorder.Export(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"k3y", "s3cret"} {
+		if strings.Contains(buffer.String(), secret) {
+			t.Fatalf("archive leaks %q:\n%s", secret, buffer.String())
+		}
+	}
+
+	var archive harArchive
+
+	if err = json.Unmarshal(buffer.Bytes(), &archive); err != nil {
+		t.Fatal(err)
+	}
+
+	request := archive.Log.Entries[0].Request
+
+	wantHeaders := map[string]string{"Authorization": redactedValue, "X-Attempt": "1"}
+
+	for _, header := range request.Headers {
+		if want, ok := wantHeaders[header.Name]; ok && header.Value == want {
+			delete(wantHeaders, header.Name)
+		}
+	}
+
+	if len(wantHeaders) > 0 {
+		t.Fatalf("headers %v missing from %v", wantHeaders, request.Headers)
+	}
+
+	wantQuery := map[string]string{"api_key": redactedValue, "page": "2"}
+
+	for _, param := range request.QueryString {
+		if param.Value != wantQuery[param.Name] {
+			t.Fatalf("got query parameter %s=%q, want %q", param.Name, param.Value, wantQuery[param.Name])
+		}
+	}
+}
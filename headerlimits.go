@@ -0,0 +1,65 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+var (
+	// ErrResponseHeaderTooLarge is returned when response headers exceed
+	// ClientConfiguration.MaxResponseHeaderBytes.
+	ErrResponseHeaderTooLarge = errors.New("response headers too large")
+	// ErrTooManyResponseHeaders is returned when a response carries more header field lines
+	// than ClientConfiguration.MaxResponseHeaders.
+	ErrTooManyResponseHeaders = errors.New("too many response headers")
+
+	// headerTooLargeErrorRegex is a regular expression to match the errors returned by net/http
+	// and golang.org/x/net/http2 when response headers exceed the configured limit. These errors
+	// aren't typed specifically so we resort to matching on the error string.
+	headerTooLargeErrorRegex = regexp.MustCompile(`server response headers exceeded \d+ bytes|header list larger than`)
+)
+
+// checkResponseHeaders enforces the response header limits on the outcome of an attempt:
+// transport errors caused by oversized headers are reported as ErrResponseHeaderTooLarge,
+// and responses with too many header field lines are closed and reported as
+// ErrTooManyResponseHeaders.
+//
+// Parameters:
+//   - res: The response of the attempt, nil if it failed.
+//   - err: The error the attempt failed with.
+//
+// Returns:
+//   - checkedRes: The response, nil if it exceeds a limit.
+//   - checkedErr: The error to report.
+func (c *Client) checkResponseHeaders(res *http.Response, err error) (checkedRes *http.Response, checkedErr error) {
+	checkedRes, checkedErr = res, err
+
+	if err != nil {
+		if headerTooLargeErrorRegex.MatchString(err.Error()) {
+			checkedErr = fmt.Errorf("%w: %w", ErrResponseHeaderTooLarge, err)
+		}
+
+		return
+	}
+
+	if c.cfg.MaxResponseHeaders <= 0 || res == nil {
+		return
+	}
+
+	count := 0
+
+	for _, values := range res.Header {
+		count += len(values)
+	}
+
+	if count > c.cfg.MaxResponseHeaders {
+		res.Body.Close()
+
+		checkedRes = nil
+		checkedErr = fmt.Errorf("%w: %d > %d", ErrTooManyResponseHeaders, count, c.cfg.MaxResponseHeaders)
+	}
+
+	return
+}
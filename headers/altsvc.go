@@ -0,0 +1,217 @@
+package headers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AlternativeService represents a single alternative service advertised through the Alt-Svc header
+// as defined in RFC 7838.
+type AlternativeService struct {
+	Protocol string        // ALPN protocol identifier of the alternative (e.g., "h2", "h3").
+	Host     string        // Host of the alternative, empty if it is the same as the origin's.
+	Port     int           // Port of the alternative.
+	MaxAge   time.Duration // Freshness lifetime of the advertisement ("ma" parameter, 24 hours by default).
+	Persist  bool          // Whether the advertisement survives network configuration changes ("persist=1").
+}
+
+// Authority returns the host:port authority of the alternative. When the alternative
+// does not name a host, the given origin host is used.
+//
+// Parameters:
+//   - originHost: The host of the origin that advertised the alternative.
+//
+// Returns:
+//   - authority: The authority to connect to.
+func (a AlternativeService) Authority(originHost string) (authority string) {
+	host := a.Host
+
+	if host == "" {
+		host = originHost
+	}
+
+	authority = net.JoinHostPort(host, strconv.Itoa(a.Port))
+
+	return
+}
+
+// DefaultAltSvcMaxAge is the freshness lifetime applied when an alternative does not carry an "ma" parameter.
+const DefaultAltSvcMaxAge = 24 * time.Hour
+
+// ErrInvalidAltSvc is returned when an Alt-Svc header value cannot be parsed.
+var ErrInvalidAltSvc = errors.New("invalid Alt-Svc value")
+
+// ParseAltSvc parses an Alt-Svc header value into the alternative services it advertises.
+//
+// Parameters:
+//   - value: The Alt-Svc header value (e.g., `h3=":443"; ma=3600, h2="alt.example.com:443"`).
+//
+// Returns:
+//   - services: The advertised alternatives, in order of preference.
+//   - cleared: True if the value is "clear", invalidating all previously advertised alternatives.
+//   - err: An error wrapping ErrInvalidAltSvc if the value is malformed.
+func ParseAltSvc(value string) (services []AlternativeService, cleared bool, err error) {
+	value = strings.TrimSpace(value)
+
+	if strings.EqualFold(value, "clear") {
+		cleared = true
+
+		return
+	}
+
+	for _, entry := range SplitListField(value) {
+		var service AlternativeService
+
+		service, err = parseAltSvcEntry(entry)
+		if err != nil {
+			return
+		}
+
+		services = append(services, service)
+	}
+
+	return
+}
+
+// parseAltSvcEntry parses a single alternative of an Alt-Svc header value.
+//
+// Parameters:
+//   - entry: The alternative (e.g., `h2="alt.example.com:443"; ma=60`).
+//
+// Returns:
+//   - service: The parsed alternative.
+//   - err: An error wrapping ErrInvalidAltSvc if the entry is malformed.
+func parseAltSvcEntry(entry string) (service AlternativeService, err error) {
+	parts := splitQuoted(entry, ';')
+
+	protocol, authority, found := strings.Cut(parts[0], "=")
+	if !found {
+		err = fmt.Errorf("%w: %q", ErrInvalidAltSvc, entry)
+
+		return
+	}
+
+	service.Protocol, err = url.PathUnescape(strings.TrimSpace(protocol))
+	if err != nil {
+		err = fmt.Errorf("%w: %q", ErrInvalidAltSvc, entry)
+
+		return
+	}
+
+	host, port, err := net.SplitHostPort(unquote(strings.TrimSpace(authority)))
+	if err != nil {
+		err = fmt.Errorf("%w: %q", ErrInvalidAltSvc, entry)
+
+		return
+	}
+
+	service.Host = host
+
+	service.Port, err = strconv.Atoi(port)
+	if err != nil || service.Port <= 0 || service.Port > 65535 {
+		err = fmt.Errorf("%w: %q", ErrInvalidAltSvc, entry)
+
+		return
+	}
+
+	service.MaxAge = DefaultAltSvcMaxAge
+
+	for _, parameter := range parts[1:] {
+		key, val, _ := strings.Cut(parameter, "=")
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = unquote(strings.TrimSpace(val))
+
+		switch key {
+		case "ma":
+			var seconds int64
+
+			seconds, err = strconv.ParseInt(val, 10, 64)
+			if err != nil || seconds < 0 {
+				err = fmt.Errorf("%w: %q", ErrInvalidAltSvc, entry)
+
+				return
+			}
+
+			service.MaxAge = time.Duration(seconds) * time.Second
+		case "persist":
+			service.Persist = val == "1"
+		}
+	}
+
+	return
+}
+
+// splitQuoted splits s on sep, ignoring separators that appear inside double-quoted strings.
+//
+// Parameters:
+//   - s: The string to split.
+//   - sep: The separator byte.
+//
+// Returns:
+//   - parts: The split parts, untrimmed.
+func splitQuoted(s string, sep byte) (parts []string) {
+	quoted := false
+	escaped := false
+	start := 0
+
+	for i := range len(s) {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\' && quoted:
+			escaped = true
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+
+			start = i + 1
+		}
+	}
+
+	parts = append(parts, s[start:])
+
+	return
+}
+
+// unquote removes surrounding double quotes from s and resolves backslash escapes.
+// Strings that are not quoted are returned unchanged.
+//
+// Parameters:
+//   - s: The possibly quoted string.
+//
+// Returns:
+//   - unquoted: The unquoted string.
+func unquote(s string) (unquoted string) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		unquoted = s
+
+		return
+	}
+
+	var builder strings.Builder
+
+	escaped := false
+
+	for i := 1; i < len(s)-1; i++ {
+		if !escaped && s[i] == '\\' {
+			escaped = true
+
+			continue
+		}
+
+		escaped = false
+
+		builder.WriteByte(s[i])
+	}
+
+	unquoted = builder.String()
+
+	return
+}
@@ -0,0 +1,170 @@
+package headers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Credentials represents the value of an Authorization or Proxy-Authorization header
+// (RFC 9110, section 11.4): an authentication scheme followed either by a token68
+// (e.g., Basic, Bearer) or by a list of auth-params (e.g., Digest).
+type Credentials struct {
+	Scheme  string            // Authentication scheme as sent (e.g., "Basic", "Digest").
+	Token68 string            // Token68 credentials, empty if the scheme uses auth-params.
+	Params  map[string]string // Auth-params keyed by lowercased name, empty if the scheme uses a token68.
+}
+
+// BasicCredentials decodes Basic credentials.
+//
+// Parameters: None.
+//
+// Returns:
+//   - username: The user-id.
+//   - password: The password.
+//   - ok: Whether the credentials use the Basic scheme and are well-formed.
+func (c Credentials) BasicCredentials() (username, password string, ok bool) {
+	if !strings.EqualFold(c.Scheme, "Basic") {
+		return
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(c.Token68)
+	if err != nil {
+		return
+	}
+
+	username, password, ok = strings.Cut(string(decoded), ":")
+
+	return
+}
+
+// ErrInvalidAuthorization is returned when an Authorization header value cannot be parsed.
+var ErrInvalidAuthorization = errors.New("invalid Authorization value")
+
+// BuildBasicAuth builds the value of an Authorization header using the Basic scheme (RFC 7617).
+//
+// Parameters:
+//   - username: The user-id. It must not contain a colon.
+//   - password: The password.
+//
+// Returns:
+//   - value: The header value (e.g., "Basic dXNlcjpwYXNz").
+func BuildBasicAuth(username, password string) (value string) {
+	value = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+
+	return
+}
+
+// BuildBearer builds the value of an Authorization header using the Bearer scheme (RFC 6750).
+//
+// Parameters:
+//   - token: The bearer token.
+//
+// Returns:
+//   - value: The header value (e.g., "Bearer mF_9.B5f-4.1JqM").
+func BuildBearer(token string) (value string) {
+	value = "Bearer " + token
+
+	return
+}
+
+// ParseAuthorization parses the value of an Authorization or Proxy-Authorization header.
+//
+// Parameters:
+//   - value: The header value (e.g., "Bearer abc", `Digest username="u", realm="r"`).
+//
+// Returns:
+//   - credentials: The parsed scheme and credentials.
+//   - err: An error wrapping ErrInvalidAuthorization if the value is malformed.
+func ParseAuthorization(value string) (credentials Credentials, err error) {
+	value = strings.TrimSpace(value)
+
+	scheme, rest, _ := strings.Cut(value, " ")
+
+	if scheme == "" || strings.ContainsAny(scheme, "=,\"") {
+		err = fmt.Errorf("%w: %q", ErrInvalidAuthorization, value)
+
+		return
+	}
+
+	credentials.Scheme = scheme
+
+	rest = strings.TrimSpace(rest)
+
+	if rest == "" {
+		return
+	}
+
+	if isToken68(rest) {
+		credentials.Token68 = rest
+
+		return
+	}
+
+	credentials.Params, err = parseAuthParams(rest)
+	if err != nil {
+		err = fmt.Errorf("%w: %q", ErrInvalidAuthorization, value)
+
+		return
+	}
+
+	return
+}
+
+// isToken68 reports whether s is a token68: one or more characters out of ALPHA, DIGIT,
+// "-", ".", "_", "~", "+" and "/", followed by any number of "=".
+//
+// Parameters:
+//   - s: The string to check.
+//
+// Returns:
+//   - is: Whether s is a token68.
+func isToken68(s string) (is bool) {
+	trimmed := strings.TrimRight(s, "=")
+
+	if trimmed == "" {
+		return
+	}
+
+	for _, r := range trimmed {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-._~+/", r) {
+			continue
+		}
+
+		return
+	}
+
+	is = true
+
+	return
+}
+
+// errMalformedAuthParam is returned by parseAuthParams for an auth-param without a value.
+var errMalformedAuthParam = errors.New("malformed auth-param")
+
+// parseAuthParams parses a comma-separated list of auth-params (name=value, where value is
+// a token or a quoted string).
+//
+// Parameters:
+//   - s: The auth-params list.
+//
+// Returns:
+//   - params: The parameters keyed by lowercased name.
+//   - err: An error if a parameter has no value.
+func parseAuthParams(s string) (params map[string]string, err error) {
+	params = make(map[string]string)
+
+	for _, element := range SplitListField(s) {
+		key, val, found := strings.Cut(element, "=")
+		if !found {
+			err = errMalformedAuthParam
+
+			return
+		}
+
+		params[strings.ToLower(strings.TrimSpace(key))] = unquote(strings.TrimSpace(val))
+	}
+
+	return
+}
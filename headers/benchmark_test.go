@@ -0,0 +1,31 @@
+package headers
+
+import "testing"
+
+// linkAllocBudget is the allocation budget of parsing linkValue, enforced by
+// TestAllocationBudgets so that regressions fail the build instead of going unnoticed. Raise it
+// only together with the change that justifies it.
+const linkAllocBudget = 24
+
+// linkValue is a Link header value as sent by paginated APIs.
+const linkValue = `<https://api.example.com/items?page=2>; rel="next", <https://api.example.com/items?page=9>; rel="last", </items?page=1>; rel="first prev"; title="First, page"`
+
+func BenchmarkParseLinkHeaderValue(b *testing.B) {
+	b.ReportAllocs()
+
+	for range b.N {
+		_, _ = ParseLinkHeaderValue(linkValue)
+	}
+}
+
+func TestAllocationBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation budgets do not hold under the race detector")
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() {
+		_, _ = ParseLinkHeaderValue(linkValue)
+	}); allocs > linkAllocBudget {
+		t.Fatalf("got %.0f allocations parsing a Link value, budget is %d", allocs, linkAllocBudget)
+	}
+}
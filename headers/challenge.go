@@ -0,0 +1,100 @@
+package headers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Challenge represents an authentication challenge of a WWW-Authenticate or Proxy-Authenticate
+// header (RFC 9110, section 11.6.1): an authentication scheme followed either by a token68
+// (e.g., Negotiate) or by a list of auth-params (e.g., Basic, Digest).
+type Challenge struct {
+	Scheme  string            // Authentication scheme as sent (e.g., "Basic", "Digest").
+	Token68 string            // Token68 data, empty if the scheme uses auth-params.
+	Params  map[string]string // Auth-params keyed by lowercased name (e.g., "realm"), empty if the scheme uses a token68.
+}
+
+// ErrInvalidChallenge is returned when a WWW-Authenticate or Proxy-Authenticate header value
+// cannot be parsed.
+var ErrInvalidChallenge = errors.New("invalid authentication challenge")
+
+// ParseChallenges parses the values of a WWW-Authenticate or Proxy-Authenticate header, each of
+// which may hold several comma-separated challenges.
+//
+// Parameters:
+//   - values: The header values (e.g., `Negotiate`, `Basic realm="r", Digest realm="r", nonce="n"`).
+//
+// Returns:
+//   - challenges: The challenges, in order of appearance.
+//   - err: An error wrapping ErrInvalidChallenge if a value is malformed.
+func ParseChallenges(values ...string) (challenges []Challenge, err error) {
+	for _, element := range SplitListField(values...) {
+		name, rest, _ := strings.Cut(element, " ")
+
+		// An element starts a new challenge with its scheme, unless it is an auth-param of
+		// the current one.
+		if strings.Contains(name, "=") {
+			if len(challenges) == 0 {
+				err = fmt.Errorf("%w: auth-param %q without a scheme", ErrInvalidChallenge, element)
+
+				return
+			}
+
+			if err = addChallengeParam(&challenges[len(challenges)-1], element); err != nil {
+				return
+			}
+
+			continue
+		}
+
+		challenge := Challenge{
+			Scheme: name,
+		}
+
+		rest = strings.TrimSpace(rest)
+
+		switch {
+		case rest == "":
+		case isToken68(rest):
+			challenge.Token68 = rest
+		default:
+			if err = addChallengeParam(&challenge, rest); err != nil {
+				return
+			}
+		}
+
+		challenges = append(challenges, challenge)
+	}
+
+	return
+}
+
+// addChallengeParam adds an auth-param (name=value, where value is a token or a quoted string)
+// to a challenge.
+//
+// Parameters:
+//   - challenge: The challenge to update.
+//   - param: The auth-param.
+//
+// Returns:
+//   - err: An error wrapping ErrInvalidChallenge if the auth-param is malformed.
+func addChallengeParam(challenge *Challenge, param string) (err error) {
+	key, val, found := strings.Cut(param, "=")
+
+	key = strings.ToLower(strings.TrimSpace(key))
+
+	if !found || key == "" {
+		err = fmt.Errorf("%w: malformed auth-param %q", ErrInvalidChallenge, param)
+
+		return
+	}
+
+	if challenge.Params == nil {
+		challenge.Params = make(map[string]string)
+	}
+
+	challenge.Params[key] = unquote(strings.TrimSpace(val))
+
+	return
+}
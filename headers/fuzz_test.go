@@ -0,0 +1,142 @@
+package headers
+
+import (
+	"errors"
+	"testing"
+)
+
+// fuzzParser fuzzes a header parser, which must not panic and must report malformed values
+// with an error wrapping its sentinel.
+func fuzzParser(f *testing.F, sentinel error, parse func(value string) error, seeds ...string) {
+	f.Helper()
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		if err := parse(value); err != nil && !errors.Is(err, sentinel) {
+			t.Fatalf("error %v does not wrap %v", err, sentinel)
+		}
+	})
+}
+
+func FuzzParseServerTiming(f *testing.F) {
+	fuzzParser(f, ErrInvalidServerTiming, func(value string) (err error) {
+		_, err = ParseServerTiming(value)
+
+		return
+	},
+		`db;dur=53, cache;desc="Cache Read";dur=23.2`,
+		`total;dur=123.4;desc="a, \"quoted\"; value"`,
+		`miss, ;dur=`,
+	)
+}
+
+func FuzzParseAltSvc(f *testing.F) {
+	fuzzParser(f, ErrInvalidAltSvc, func(value string) (err error) {
+		_, _, err = ParseAltSvc(value)
+
+		return
+	},
+		`h3=":443"; ma=86400, h2="alt.example.com:443"; persist=1`,
+		`clear`,
+		`h3="[::1]:443"; ma=`,
+	)
+}
+
+func FuzzParseNEL(f *testing.F) {
+	fuzzParser(f, ErrInvalidNEL, func(value string) (err error) {
+		_, err = ParseNEL(value)
+
+		return
+	},
+		`{"report_to":"default","max_age":2592000,"success_fraction":0.5}`,
+		`{"max_age":-1}`,
+	)
+}
+
+func FuzzParseReportTo(f *testing.F) {
+	fuzzParser(f, ErrInvalidReportTo, func(value string) (err error) {
+		_, err = ParseReportTo(value)
+
+		return
+	},
+		`{"group":"default","max_age":10886400,"endpoints":[{"url":"https://example.com/reports"}]}`,
+		`{"max_age":1}, {"group":"b","endpoints":[]}`,
+	)
+}
+
+func FuzzParseReportingEndpoints(f *testing.F) {
+	fuzzParser(f, ErrInvalidReportingEndpoints, func(value string) (err error) {
+		_, err = ParseReportingEndpoints(value)
+
+		return
+	},
+		`default="https://example.com/reports", csp="/csp"`,
+		`a=b, c`,
+	)
+}
+
+func FuzzParseLinkHeaderValue(f *testing.F) {
+	fuzzParser(f, ErrInvalidLink, func(value string) (err error) {
+		_, err = ParseLinkHeaderValue(value)
+
+		return
+	},
+		`</page/2>; rel="next", </page/9>; rel="last"`,
+		`<u>; title="a, b"; rel=next`,
+		`</a,b;c>; title*=UTF-8'en'%E2%82%AC%20rates`,
+		`<unterminated; rel=next`,
+	)
+}
+
+func TestParseLinkHeaderValueQuotedSeparators(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		wantURIs  []string
+		wantTitle string
+	}{
+		{
+			name:      "comma in a quoted value",
+			value:     `<u>; title="a, b", <v>; rel=next`,
+			wantURIs:  []string{"u", "v"},
+			wantTitle: "a, b",
+		},
+		{
+			name:      "semicolon in a quoted value",
+			value:     `<u>; title="a; b"; rel=next`,
+			wantURIs:  []string{"u"},
+			wantTitle: "a; b",
+		},
+		{
+			name:     "separators in the target",
+			value:    `</a,b;c>; rel=next`,
+			wantURIs: []string{"/a,b;c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := ParseLinkHeaderValue(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(links) != len(tt.wantURIs) {
+				t.Fatalf("got %d links, want %d", len(links), len(tt.wantURIs))
+			}
+
+			for i, link := range links {
+				if link.URI != tt.wantURIs[i] {
+					t.Fatalf("got link %d to %q, want %q", i, link.URI, tt.wantURIs[i])
+				}
+			}
+
+			if links[0].Title != tt.wantTitle {
+				t.Fatalf("got title %q, want %q", links[0].Title, tt.wantTitle)
+			}
+		})
+	}
+}
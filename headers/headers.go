@@ -12,6 +12,8 @@ const (
 	// Authentication - These header fields are used for authentication and authorization.
 	// They are commonly found in request messages where the client needs to authenticate with the server.
 	Authorization      Header = "Authorization"       // Used to pass authentication credentials in the form of bearer tokens or other mechanisms.
+	DPoP               Header = "DPoP"                // Carries a proof of possession of the key an OAuth 2.0 access token is bound to (RFC 9449).
+	DPoPNonce          Header = "DPoP-Nonce"          // Sent by the server with a nonce to include in subsequent DPoP proofs (RFC 9449).
 	ProxyAuthenticate  Header = "Proxy-Authenticate"  // Used in responses to indicate that the client must authenticate with the proxy.
 	ProxyAuthorization Header = "Proxy-Authorization" // Used in requests to provide proxy authentication credentials.
 	WWWAuthenticate    Header = "WWW-Authenticate"    // Sent by the server in responses, indicating the required authentication method.
@@ -136,11 +138,12 @@ const (
 	XXSSProtection                  Header = "X-XSS-Protection"                    // Enables or disables cross-site scripting (XSS) protection.
 
 	// Server-sent event - These headers are related to the Server-Sent Events (SSE) protocol.
-	LastEventID Header = "Last-Event-ID" // Identifies the last event received from the server in SSE.
-	NEL         Header = "NEL"           // Network Error Logging configuration.
-	PingFrom    Header = "Ping-From"     // The origin initiating the ping request.
-	PingTo      Header = "Ping-To"       // The target URL for the ping request.
-	ReportTo    Header = "Report-To"     // Specifies where to send violation reports.
+	LastEventID        Header = "Last-Event-ID"       // Identifies the last event received from the server in SSE.
+	NEL                Header = "NEL"                 // Network Error Logging configuration.
+	PingFrom           Header = "Ping-From"           // The origin initiating the ping request.
+	PingTo             Header = "Ping-To"             // The target URL for the ping request.
+	ReportTo           Header = "Report-To"           // Specifies where to send violation reports.
+	ReportingEndpoints Header = "Reporting-Endpoints" // Names the endpoints reports can be delivered to (Reporting API v1).
 
 	// Transfer coding - These headers control transfer encoding behavior.
 	TE               Header = "TE"                // Specifies the transfer encodings the client is willing to accept.
@@ -170,9 +173,11 @@ const (
 	Signature           Header = "Signature"              // Provides a digital signature for the request.
 	SignedHeaders       Header = "Signed-Headers"         // Lists headers covered by the signature.
 	SourceMap           Header = "SourceMap"              // Points to the source map of a JavaScript resource.
+	Traceparent         Header = "Traceparent"            // Carries the trace context of the request (W3C Trace Context).
 	Upgrade             Header = "Upgrade"                // Indicates that the client wishes to switch to another protocol.
 	XDNSPrefetchControl Header = "X-DNS-Prefetch-Control" // Controls DNS prefetching.
 	XPingback           Header = "X-Pingback"             // Specifies the URL for pingback.
+	XRequestID          Header = "X-Request-ID"           // Identifies the request, for correlation with server logs.
 	XRequestedWith      Header = "X-Requested-With"       // Identifies requests made via JavaScript libraries.
 	XRobotsTag          Header = "X-Robots-Tag"           // Controls indexing and crawling by web crawlers.
 	XUACompatible       Header = "X-UA-Compatible"        // Specifies the document's compatibility mode for browsers.
@@ -0,0 +1,82 @@
+package headers
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// BuildAcceptLanguage builds an Accept-Language header value from the given language tags.
+// Tags are expected in order of preference: the first tag is sent without a quality value
+// (implying q=1) and every following tag gets a quality value 0.1 lower than the previous one,
+// never going below q=0.1.
+//
+// Parameters:
+//   - tags: The language tags, most preferred first.
+//
+// Returns:
+//   - value: The Accept-Language header value (e.g., "fr-CH, fr;q=0.9, en;q=0.8").
+func BuildAcceptLanguage(tags ...language.Tag) (value string) {
+	entries := make([]string, 0, len(tags))
+
+	for i, tag := range tags {
+		if i == 0 {
+			entries = append(entries, tag.String())
+
+			continue
+		}
+
+		q := 10 - i
+
+		if q < 1 {
+			q = 1
+		}
+
+		entries = append(entries, tag.String()+";q=0."+strconv.Itoa(q))
+	}
+
+	value = strings.Join(entries, ", ")
+
+	return
+}
+
+// ParseAcceptLanguage parses an Accept-Language header value into language tags ordered by
+// their quality values, highest first, together with the matching quality values.
+//
+// Parameters:
+//   - value: The Accept-Language header value.
+//
+// Returns:
+//   - tags: The language tags, most preferred first.
+//   - q: The quality value of each tag.
+//   - err: An error if the value is malformed.
+func ParseAcceptLanguage(value string) (tags []language.Tag, q []float32, err error) {
+	tags, q, err = language.ParseAcceptLanguage(value)
+
+	return
+}
+
+// ParseContentLanguage parses a Content-Language header value, a comma-separated list of
+// language tags describing the intended audience of the content.
+//
+// Parameters:
+//   - value: The Content-Language header value (e.g., "de-DE, en-CA").
+//
+// Returns:
+//   - tags: The language tags in the order they appear.
+//   - err: An error if any of the tags is malformed.
+func ParseContentLanguage(value string) (tags []language.Tag, err error) {
+	for _, entry := range SplitListField(value) {
+		var tag language.Tag
+
+		tag, err = language.Parse(entry)
+		if err != nil {
+			return
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return
+}
@@ -0,0 +1,322 @@
+package headers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.source.hueristiq.com/http/mime"
+)
+
+// ParsedLink represents a single link of a Link header as defined in RFC 8288.
+type ParsedLink struct {
+	URI      string            // Target reference as it appears between angle brackets, possibly relative.
+	Rels     []string          // Relation types, lowercased ("rel" can hold several, space-separated).
+	Anchor   string            // Context reference overriding the link context, empty if absent.
+	Hreflang []string          // Languages of the target ("hreflang" may appear several times).
+	Type     string            // Media type hint of the target, empty if absent.
+	Title    string            // Human readable label, "title*" taking precedence over "title".
+	Params   map[string]string // All parameters keyed by lowercased name, first occurrence only.
+}
+
+// HasRel reports whether the link has the given relation type.
+//
+// Parameters:
+//   - rel: The relation type, matched case-insensitively.
+//
+// Returns:
+//   - has: Whether the link has the relation type.
+func (l ParsedLink) HasRel(rel string) (has bool) {
+	rel = strings.ToLower(rel)
+
+	for _, r := range l.Rels {
+		if r == rel {
+			has = true
+
+			return
+		}
+	}
+
+	return
+}
+
+// ResolveAgainst resolves the link target against the URL of the resource the Link header
+// was received with, so relative targets become usable.
+//
+// Parameters:
+//   - base: The URL of the response the link was found in.
+//
+// Returns:
+//   - target: The absolute target URL.
+//   - err: An error if the target reference cannot be parsed.
+func (l ParsedLink) ResolveAgainst(base *url.URL) (target *url.URL, err error) {
+	reference, err := url.Parse(l.URI)
+	if err != nil {
+		return
+	}
+
+	target = base.ResolveReference(reference)
+
+	return
+}
+
+// ContextAgainst returns the link context: the URL of the resource the Link header was
+// received with or, if the link has an anchor, the anchor resolved against it.
+//
+// Parameters:
+//   - base: The URL of the response the link was found in.
+//
+// Returns:
+//   - context: The absolute context URL.
+//   - err: An error if the anchor cannot be parsed.
+func (l ParsedLink) ContextAgainst(base *url.URL) (context *url.URL, err error) {
+	if l.Anchor == "" {
+		context = base
+
+		return
+	}
+
+	reference, err := url.Parse(l.Anchor)
+	if err != nil {
+		return
+	}
+
+	context = base.ResolveReference(reference)
+
+	return
+}
+
+// ParsedLinks is the list of links carried by one or more Link header values.
+type ParsedLinks []ParsedLink
+
+// Rel returns the first link with the given relation type.
+//
+// Parameters:
+//   - rel: The relation type (e.g., "next"), matched case-insensitively.
+//
+// Returns:
+//   - link: The first matching link.
+//   - ok: Whether a matching link exists.
+func (l ParsedLinks) Rel(rel string) (link ParsedLink, ok bool) {
+	for _, candidate := range l {
+		if candidate.HasRel(rel) {
+			link, ok = candidate, true
+
+			return
+		}
+	}
+
+	return
+}
+
+// ByType returns the links whose type hint matches the given media type. Parameters of
+// the type hint (e.g., "; charset=utf-8") are ignored and the comparison is case-insensitive.
+//
+// Parameters:
+//   - media: The media type (e.g., mime.JSON).
+//
+// Returns:
+//   - links: The matching links, in order of appearance.
+func (l ParsedLinks) ByType(media mime.MIME) (links ParsedLinks) {
+	for _, candidate := range l {
+		essence, _, _ := strings.Cut(candidate.Type, ";")
+
+		if strings.EqualFold(strings.TrimSpace(essence), media.String()) {
+			links = append(links, candidate)
+		}
+	}
+
+	return
+}
+
+// GroupByRel groups the links by relation type. A link with several relation types
+// appears in the group of each of them.
+//
+// Parameters: None.
+//
+// Returns:
+//   - groups: The links keyed by lowercased relation type, in order of appearance.
+func (l ParsedLinks) GroupByRel() (groups map[string]ParsedLinks) {
+	groups = make(map[string]ParsedLinks)
+
+	for _, link := range l {
+		for _, rel := range link.Rels {
+			groups[rel] = append(groups[rel], link)
+		}
+	}
+
+	return
+}
+
+// ErrInvalidLink is returned when a Link header value cannot be parsed.
+var ErrInvalidLink = errors.New("invalid Link value")
+
+// ParseLinkHeaderValue parses one or more Link header values into their links. Commas and
+// semicolons inside quoted parameter values and inside the target reference are handled.
+//
+// Parameters:
+//   - values: The Link header values (e.g., `</page/2>; rel="next", </page/9>; rel="last"`).
+//
+// Returns:
+//   - links: The parsed links, in order of appearance.
+//   - err: An error wrapping ErrInvalidLink if a value is malformed.
+func ParseLinkHeaderValue(values ...string) (links ParsedLinks, err error) {
+	for _, value := range values {
+		for _, entry := range splitLinks(value) {
+			entry = strings.TrimSpace(entry)
+
+			if entry == "" {
+				continue
+			}
+
+			var link ParsedLink
+
+			link, err = parseLink(entry)
+			if err != nil {
+				return
+			}
+
+			links = append(links, link)
+		}
+	}
+
+	return
+}
+
+// splitLinks splits a Link header value into link-values, ignoring commas inside the
+// target reference and inside quoted parameter values.
+//
+// Parameters:
+//   - value: The Link header value.
+//
+// Returns:
+//   - entries: The link-values, untrimmed.
+func splitLinks(value string) (entries []string) {
+	start := 0
+	inURI := false
+	quoted := false
+	escaped := false
+
+	for i := range len(value) {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && value[i] == '\\':
+			escaped = true
+		case quoted:
+			quoted = value[i] != '"'
+		case inURI:
+			inURI = value[i] != '>'
+		case value[i] == '<':
+			inURI = true
+		case value[i] == '"':
+			quoted = true
+		case value[i] == ',':
+			entries = append(entries, value[start:i])
+
+			start = i + 1
+		}
+	}
+
+	entries = append(entries, value[start:])
+
+	return
+}
+
+// parseLink parses a single link-value.
+//
+// Parameters:
+//   - entry: The trimmed link-value (e.g., `</page/2>; rel="next"`).
+//
+// Returns:
+//   - link: The parsed link.
+//   - err: An error wrapping ErrInvalidLink if the link-value is malformed.
+func parseLink(entry string) (link ParsedLink, err error) {
+	end := strings.IndexByte(entry, '>')
+
+	if !strings.HasPrefix(entry, "<") || end < 0 {
+		err = fmt.Errorf("%w: %q", ErrInvalidLink, entry)
+
+		return
+	}
+
+	link.URI = strings.TrimSpace(entry[1:end])
+	link.Params = make(map[string]string)
+
+	rest := strings.TrimSpace(entry[end+1:])
+
+	if rest != "" && !strings.HasPrefix(rest, ";") {
+		err = fmt.Errorf("%w: %q", ErrInvalidLink, entry)
+
+		return
+	}
+
+	for _, parameter := range splitQuoted(rest, ';') {
+		parameter = strings.TrimSpace(parameter)
+
+		if parameter == "" {
+			continue
+		}
+
+		key, val, _ := strings.Cut(parameter, "=")
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = unquote(strings.TrimSpace(val))
+
+		if key == "hreflang" {
+			link.Hreflang = append(link.Hreflang, val)
+		}
+
+		if _, exists := link.Params[key]; exists {
+			continue
+		}
+
+		link.Params[key] = val
+
+		switch key {
+		case "rel":
+			link.Rels = strings.Fields(strings.ToLower(val))
+		case "anchor":
+			link.Anchor = val
+		case "type":
+			link.Type = val
+		case "title":
+			if link.Title == "" {
+				link.Title = val
+			}
+		case "title*":
+			if decoded, ok := decodeExtValue(val); ok {
+				link.Title = decoded
+			}
+		}
+	}
+
+	return
+}
+
+// decodeExtValue decodes an RFC 8187 ext-value (charset'language'percent-encoded) whose
+// charset is UTF-8.
+//
+// Parameters:
+//   - value: The ext-value (e.g., "UTF-8'de'n%c3%a4chstes%20Kapitel").
+//
+// Returns:
+//   - decoded: The decoded value.
+//   - ok: Whether the value could be decoded.
+func decodeExtValue(value string) (decoded string, ok bool) {
+	parts := strings.SplitN(value, "'", 3)
+
+	if len(parts) != 3 || !strings.EqualFold(parts[0], "UTF-8") {
+		return
+	}
+
+	decoded, err := url.PathUnescape(parts[2])
+	if err != nil {
+		return
+	}
+
+	ok = true
+
+	return
+}
@@ -0,0 +1,173 @@
+package headers
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseLinkHeaderValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    ParsedLinks
+		wantErr error
+	}{
+		{
+			name:   "several relation types",
+			values: []string{`</u>; rel="next  Last prefetch"`},
+			want: ParsedLinks{
+				{
+					URI:    "/u",
+					Rels:   []string{"next", "last", "prefetch"},
+					Params: map[string]string{"rel": "next  Last prefetch"},
+				},
+			},
+		},
+		{
+			name:   "anchor",
+			values: []string{`<#section>; rel=bookmark; anchor="/doc"`},
+			want: ParsedLinks{
+				{
+					URI:    "#section",
+					Rels:   []string{"bookmark"},
+					Anchor: "/doc",
+					Params: map[string]string{"rel": "bookmark", "anchor": "/doc"},
+				},
+			},
+		},
+		{
+			name:   "hreflang and type",
+			values: []string{`</fr>; rel=alternate; hreflang=fr; hreflang=fr-CA; type="text/html; charset=utf-8"`},
+			want: ParsedLinks{
+				{
+					URI:      "/fr",
+					Rels:     []string{"alternate"},
+					Hreflang: []string{"fr", "fr-CA"},
+					Type:     "text/html; charset=utf-8",
+					Params:   map[string]string{"rel": "alternate", "hreflang": "fr", "type": "text/html; charset=utf-8"},
+				},
+			},
+		},
+		{
+			name:   "several values",
+			values: []string{`</page/2>; rel="next"`, `</page/9>; rel="last"`},
+			want: ParsedLinks{
+				{
+					URI:    "/page/2",
+					Rels:   []string{"next"},
+					Params: map[string]string{"rel": "next"},
+				},
+				{
+					URI:    "/page/9",
+					Rels:   []string{"last"},
+					Params: map[string]string{"rel": "last"},
+				},
+			},
+		},
+		{
+			name:    "unterminated target",
+			values:  []string{`</page/2; rel=next`},
+			wantErr: ErrInvalidLink,
+		},
+		{
+			name:    "missing parameter separator",
+			values:  []string{`</page/2> rel=next`},
+			wantErr: ErrInvalidLink,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := ParseLinkHeaderValue(tt.values...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(links, tt.want) {
+				t.Fatalf("got %+v, want %+v", links, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsedLinkResolveAgainst(t *testing.T) {
+	base, err := url.Parse("https://example.com/docs/page?q=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		value       string
+		wantTarget  string
+		wantContext string
+		wantErr     bool
+	}{
+		{
+			name:        "relative target",
+			value:       `<next>; rel=next`,
+			wantTarget:  "https://example.com/docs/next",
+			wantContext: "https://example.com/docs/page?q=1",
+		},
+		{
+			name:        "absolute path target",
+			value:       `</api/items?page=2>; rel=next`,
+			wantTarget:  "https://example.com/api/items?page=2",
+			wantContext: "https://example.com/docs/page?q=1",
+		},
+		{
+			name:        "absolute target",
+			value:       `<https://cdn.example.com/style.css>; rel=preload`,
+			wantTarget:  "https://cdn.example.com/style.css",
+			wantContext: "https://example.com/docs/page?q=1",
+		},
+		{
+			name:        "anchored context",
+			value:       `<../license>; rel=license; anchor="#about"`,
+			wantTarget:  "https://example.com/license",
+			wantContext: "https://example.com/docs/page?q=1#about",
+		},
+		{
+			name:    "invalid target",
+			value:   `<%zz>; rel=next`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := ParseLinkHeaderValue(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			target, err := links[0].ResolveAgainst(base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if target.String() != tt.wantTarget {
+				t.Fatalf("got target %q, want %q", target, tt.wantTarget)
+			}
+
+			context, err := links[0].ContextAgainst(base)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if context.String() != tt.wantContext {
+				t.Fatalf("got context %q, want %q", context, tt.wantContext)
+			}
+		})
+	}
+}
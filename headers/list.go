@@ -0,0 +1,133 @@
+package headers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SplitListField splits list-based field values (RFC 9110, section 5.6.1) into their elements.
+// Commas inside quoted strings do not split elements, surrounding whitespace is trimmed and
+// empty elements are dropped. Several values of the same field are treated as one list.
+//
+// Parameters:
+//   - values: The field values (e.g., `gzip, br;q=0.8`, `text/html; title="a, b"`).
+//
+// Returns:
+//   - elements: The list elements, in order of appearance.
+func SplitListField(values ...string) (elements []string) {
+	for _, value := range values {
+		for _, element := range splitQuoted(value, ',') {
+			element = strings.TrimSpace(element)
+
+			if element == "" {
+				continue
+			}
+
+			elements = append(elements, element)
+		}
+	}
+
+	return
+}
+
+// ErrInvalidQValue is returned when a quality value does not match RFC 9110, section 12.4.2.
+var ErrInvalidQValue = errors.New("invalid quality value")
+
+// ParseQValue parses a quality value ("weight"), a number between 0 and 1 with at most
+// three decimals.
+//
+// Parameters:
+//   - value: The quality value, without the "q=" prefix (e.g., "0.8").
+//
+// Returns:
+//   - q: The parsed quality value.
+//   - err: An error wrapping ErrInvalidQValue if the value is malformed or out of range.
+func ParseQValue(value string) (q float64, err error) {
+	integer, fraction, hasFraction := strings.Cut(value, ".")
+
+	valid := (integer == "0" || integer == "1") && len(fraction) <= 3
+
+	for _, r := range fraction {
+		valid = valid && r >= '0' && r <= '9'
+	}
+
+	if hasFraction && integer == "1" {
+		valid = valid && strings.Trim(fraction, "0") == ""
+	}
+
+	if !valid {
+		err = fmt.Errorf("%w: %q", ErrInvalidQValue, value)
+
+		return
+	}
+
+	q, err = strconv.ParseFloat(value, 64)
+	if err != nil {
+		err = fmt.Errorf("%w: %q", ErrInvalidQValue, value)
+
+		return
+	}
+
+	return
+}
+
+// WeightedValue is an element of a list-based field whose elements carry quality values,
+// such as Accept, Accept-Encoding, Accept-Language and TE.
+type WeightedValue struct {
+	Value  string            // The element without its parameters (e.g., "text/html", "gzip").
+	Q      float64           // The quality value, 1 if absent.
+	Params map[string]string // Parameters other than "q", keyed by lowercased name.
+}
+
+// ParseWeightedList parses list-based field values whose elements carry quality values into
+// WeightedValue entries sorted by descending quality. Elements of equal quality keep their
+// order of appearance.
+//
+// Parameters:
+//   - values: The field values (e.g., `text/html, application/json;q=0.9, */*;q=0.1`).
+//
+// Returns:
+//   - weighted: The parsed elements, most preferred first.
+//   - err: An error wrapping ErrInvalidQValue if an element carries a malformed quality value.
+func ParseWeightedList(values ...string) (weighted []WeightedValue, err error) {
+	for _, element := range SplitListField(values...) {
+		parts := splitQuoted(element, ';')
+
+		entry := WeightedValue{
+			Value:  strings.TrimSpace(parts[0]),
+			Q:      1,
+			Params: make(map[string]string),
+		}
+
+		for _, parameter := range parts[1:] {
+			key, val, _ := strings.Cut(parameter, "=")
+
+			key = strings.ToLower(strings.TrimSpace(key))
+			val = strings.TrimSpace(val)
+
+			if key == "q" {
+				entry.Q, err = ParseQValue(val)
+				if err != nil {
+					return
+				}
+
+				continue
+			}
+
+			if key != "" {
+				entry.Params[key] = unquote(val)
+			}
+		}
+
+		weighted = append(weighted, entry)
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].Q > weighted[j].Q
+	})
+
+	return
+}
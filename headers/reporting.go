@@ -0,0 +1,132 @@
+package headers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NetworkErrorLogging represents the policy delivered through the NEL header
+// as defined by the W3C Network Error Logging specification.
+//
+//nolint:tagliatelle // Field names are defined by the specification.
+type NetworkErrorLogging struct {
+	ReportTo          string   `json:"report_to"`                  // Name of the Report-To group reports are delivered to.
+	MaxAge            int64    `json:"max_age"`                    // Lifetime of the policy in seconds, 0 removes the policy.
+	IncludeSubdomains bool     `json:"include_subdomains"`         // Whether the policy applies to subdomains of the origin.
+	SuccessFraction   *float64 `json:"success_fraction,omitempty"` // Sampling rate for successful requests, nil if not set.
+	FailureFraction   *float64 `json:"failure_fraction,omitempty"` // Sampling rate for failed requests, nil if not set.
+	RequestHeaders    []string `json:"request_headers,omitempty"`  // Request headers to include in reports.
+	ResponseHeaders   []string `json:"response_headers,omitempty"` // Response headers to include in reports.
+}
+
+// ReportToGroup represents a single endpoint group delivered through the Report-To header
+// as defined by the (legacy) W3C Reporting API.
+//
+//nolint:tagliatelle // Field names are defined by the specification.
+type ReportToGroup struct {
+	Group             string             `json:"group,omitempty"`    // Name of the group, "default" if empty.
+	MaxAge            int64              `json:"max_age"`            // Lifetime of the group in seconds.
+	IncludeSubdomains bool               `json:"include_subdomains"` // Whether the group applies to subdomains of the origin.
+	Endpoints         []ReportToEndpoint `json:"endpoints"`          // Endpoints reports can be delivered to.
+}
+
+// ReportToEndpoint represents an endpoint of a Report-To group.
+type ReportToEndpoint struct {
+	URL      string `json:"url"`                // URL reports are delivered to.
+	Priority int    `json:"priority,omitempty"` // Failover class of the endpoint.
+	Weight   int    `json:"weight,omitempty"`   // Load balancing weight of the endpoint within its class.
+}
+
+var (
+	// ErrInvalidNEL is returned when a NEL header value cannot be parsed.
+	ErrInvalidNEL = errors.New("invalid NEL value")
+
+	// ErrInvalidReportTo is returned when a Report-To header value cannot be parsed.
+	ErrInvalidReportTo = errors.New("invalid Report-To value")
+
+	// ErrInvalidReportingEndpoints is returned when a Reporting-Endpoints header value cannot be parsed.
+	ErrInvalidReportingEndpoints = errors.New("invalid Reporting-Endpoints value")
+)
+
+// ParseNEL parses a NEL header value into a NetworkErrorLogging policy.
+//
+// Parameters:
+//   - value: The NEL header value (e.g., `{"report_to":"network-errors","max_age":2592000}`).
+//
+// Returns:
+//   - policy: The parsed policy.
+//   - err: An error wrapping ErrInvalidNEL if the value is malformed.
+func ParseNEL(value string) (policy NetworkErrorLogging, err error) {
+	if err = json.Unmarshal([]byte(value), &policy); err != nil {
+		err = fmt.Errorf("%w: %w", ErrInvalidNEL, err)
+
+		return
+	}
+
+	if policy.ReportTo == "" && policy.MaxAge != 0 {
+		err = fmt.Errorf("%w: missing report_to", ErrInvalidNEL)
+
+		return
+	}
+
+	return
+}
+
+// ParseReportTo parses a Report-To header value, a comma-separated list of JSON objects,
+// into the endpoint groups it defines.
+//
+// Parameters:
+//   - value: The Report-To header value.
+//
+// Returns:
+//   - groups: The parsed endpoint groups. Groups without a name are named "default".
+//   - err: An error wrapping ErrInvalidReportTo if the value is malformed.
+func ParseReportTo(value string) (groups []ReportToGroup, err error) {
+	if err = json.Unmarshal([]byte("["+value+"]"), &groups); err != nil {
+		err = fmt.Errorf("%w: %w", ErrInvalidReportTo, err)
+
+		return
+	}
+
+	for i := range groups {
+		if groups[i].Group == "" {
+			groups[i].Group = "default"
+		}
+	}
+
+	return
+}
+
+// ParseReportingEndpoints parses a Reporting-Endpoints header value, a structured dictionary
+// mapping endpoint names to quoted URLs.
+//
+// Parameters:
+//   - value: The Reporting-Endpoints header value (e.g., `default="https://example.com/reports"`).
+//
+// Returns:
+//   - endpoints: The endpoint URLs keyed by endpoint name.
+//   - err: An error wrapping ErrInvalidReportingEndpoints if the value is malformed.
+func ParseReportingEndpoints(value string) (endpoints map[string]string, err error) {
+	endpoints = make(map[string]string)
+
+	for _, entry := range SplitListField(value) {
+		// Structured field parameters after the URL carry no meaning for endpoints.
+		entry = splitQuoted(entry, ';')[0]
+
+		name, endpoint, found := strings.Cut(entry, "=")
+
+		endpoint = strings.TrimSpace(endpoint)
+
+		if !found || !strings.HasPrefix(endpoint, `"`) {
+			err = fmt.Errorf("%w: %q", ErrInvalidReportingEndpoints, entry)
+
+			return
+		}
+
+		endpoints[strings.TrimSpace(name)] = unquote(endpoint)
+	}
+
+	return
+}
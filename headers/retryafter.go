@@ -0,0 +1,44 @@
+package headers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRetryAfter is returned when a Retry-After header value cannot be parsed.
+var ErrInvalidRetryAfter = errors.New("invalid Retry-After value")
+
+// ParseRetryAfter parses a Retry-After header value (RFC 9110, section 10.2.3), given either
+// as delay-seconds or as an HTTP-date, into the delay to wait before retrying.
+//
+// Parameters:
+//   - value: The Retry-After header value (e.g., "120", "Fri, 31 Dec 1999 23:59:59 GMT").
+//   - now: The time the delay of an HTTP-date is computed from, usually time.Now().
+//
+// Returns:
+//   - delay: The delay, zero for HTTP-dates in the past.
+//   - err: An error wrapping ErrInvalidRetryAfter if the value is malformed.
+func ParseRetryAfter(value string, now time.Time) (delay time.Duration, err error) {
+	value = strings.TrimSpace(value)
+
+	if seconds, errr := strconv.ParseUint(value, 10, 32); errr == nil {
+		delay = time.Duration(seconds) * time.Second
+
+		return
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		err = fmt.Errorf("%w: %q", ErrInvalidRetryAfter, value)
+
+		return
+	}
+
+	delay = max(date.Sub(now), 0)
+
+	return
+}
@@ -0,0 +1,95 @@
+package headers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerTimingMetric represents a single metric reported through the Server-Timing header
+// as defined by the W3C Server Timing specification.
+type ServerTimingMetric struct {
+	Name        string        // Name of the metric (e.g., "db").
+	Duration    time.Duration // Duration reported through the "dur" parameter, zero if absent.
+	Description string        // Description reported through the "desc" parameter, empty if absent.
+}
+
+// ErrInvalidServerTiming is returned when a Server-Timing header value cannot be parsed.
+var ErrInvalidServerTiming = errors.New("invalid Server-Timing value")
+
+// ParseServerTiming parses one or more Server-Timing header values into the metrics they report.
+// Unknown parameters are ignored, and only the first occurrence of "dur" and "desc" is honored.
+//
+// Parameters:
+//   - values: The Server-Timing header values (e.g., `db;dur=53, cache;desc="Cache Read";dur=23.2`).
+//
+// Returns:
+//   - metrics: The reported metrics, in order of appearance.
+//   - err: An error wrapping ErrInvalidServerTiming if a value is malformed.
+func ParseServerTiming(values ...string) (metrics []ServerTimingMetric, err error) {
+	for _, entry := range SplitListField(values...) {
+		var metric ServerTimingMetric
+
+		metric, err = parseServerTimingEntry(entry)
+		if err != nil {
+			return
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	return
+}
+
+// parseServerTimingEntry parses a single metric of a Server-Timing header value.
+//
+// Parameters:
+//   - entry: The metric (e.g., `cache;desc="Cache Read";dur=23.2`).
+//
+// Returns:
+//   - metric: The parsed metric.
+//   - err: An error wrapping ErrInvalidServerTiming if the entry is malformed.
+func parseServerTimingEntry(entry string) (metric ServerTimingMetric, err error) {
+	parts := splitQuoted(entry, ';')
+
+	metric.Name = strings.TrimSpace(parts[0])
+
+	if metric.Name == "" || strings.ContainsAny(metric.Name, "\"= ") {
+		err = fmt.Errorf("%w: %q", ErrInvalidServerTiming, entry)
+
+		return
+	}
+
+	var hasDuration, hasDescription bool
+
+	for _, parameter := range parts[1:] {
+		key, val, _ := strings.Cut(parameter, "=")
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = unquote(strings.TrimSpace(val))
+
+		switch {
+		case key == "dur" && !hasDuration:
+			hasDuration = true
+
+			var milliseconds float64
+
+			milliseconds, err = strconv.ParseFloat(val, 64)
+			if err != nil {
+				err = fmt.Errorf("%w: %q", ErrInvalidServerTiming, entry)
+
+				return
+			}
+
+			metric.Duration = time.Duration(milliseconds * float64(time.Millisecond))
+		case key == "desc" && !hasDescription:
+			hasDescription = true
+
+			metric.Description = val
+		}
+	}
+
+	return
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// hedgeableMethods are the methods whose requests may be sent twice at once, being idempotent.
+var hedgeableMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// hedgeOutcome is the outcome of one of the attempts sent by sendHedged.
+type hedgeOutcome struct {
+	index int
+	res   *http.Response
+	err   error
+}
+
+// hedgedBody is the body of the response that won a hedged attempt, releasing the context of
+// its attempt when closed.
+type hedgedBody struct {
+	io.ReadCloser
+
+	cancel context.CancelFunc
+}
+
+// Close closes the body and releases the context of its attempt.
+//
+// Parameters: None.
+//
+// Returns:
+//   - err: The error closing the body.
+func (b *hedgedBody) Close() (err error) {
+	err = b.ReadCloser.Close()
+
+	b.cancel()
+
+	return
+}
+
+// isHedgeable reports whether a request may be hedged: hedging is enabled and the request is
+// an idempotent one without a body.
+//
+// Parameters:
+//   - req: The request.
+//
+// Returns:
+//   - hedgeable: Whether a duplicate of the request may be sent.
+func (c *Client) isHedgeable(req *Request) (hedgeable bool) {
+	hedgeable = c.cfg.HedgeDelay > 0 &&
+		(req.Body == nil || req.Body == http.NoBody) &&
+		slices.Contains(hedgeableMethods, req.Method)
+
+	return
+}
+
+// sendHedged sends an attempt and, if the request is hedgeable and no response arrived within
+// ClientConfiguration.HedgeDelay, a duplicate of it. The first response wins and the other
+// attempt is canceled. An attempt failing while the other one is pending lets the other one
+// win; if both fail, the error of the last one is returned.
+//
+// Parameters:
+//   - ctx: The context of the attempt.
+//   - httpClient: The HTTP client to send the attempts through.
+//   - req: The request.
+//   - monitor: The rate monitor of the attempt, nil if disabled.
+//
+// Returns:
+//   - res: The winning response, nil if both attempts failed.
+//   - winner: The rate monitor of the winning attempt, monitor if both failed.
+//   - err: The error the attempts failed with.
+func (c *Client) sendHedged(ctx context.Context, httpClient *http.Client, req *Request, monitor *rateMonitor) (res *http.Response, winner *rateMonitor, err error) {
+	winner = monitor
+
+	if !c.isHedgeable(req) {
+		res, err = c.sendAttempt(ctx, httpClient, req, monitor)
+
+		return
+	}
+
+	var cancels [2]context.CancelFunc
+
+	monitors := [2]*rateMonitor{monitor, nil}
+
+	outcomes := make(chan hedgeOutcome, len(cancels))
+
+	send := func(ctx context.Context, index int) {
+		ctx, cancels[index] = context.WithCancel(ctx)
+
+		go func() {
+			attemptRes, attemptErr := c.sendAttempt(ctx, httpClient, req, monitors[index])
+
+			outcomes <- hedgeOutcome{index: index, res: attemptRes, err: attemptErr}
+		}()
+	}
+
+	send(ctx, 0)
+
+	timer := time.NewTimer(c.cfg.HedgeDelay)
+
+	defer timer.Stop()
+
+	for pending := 1; pending > 0; {
+		select {
+		case <-timer.C:
+			// The duplicate gets its own monitor, derived from the request context rather
+			// than from the attempt context, which carries the hooks of the first attempt.
+			hedgeCtx, hedgeMonitor := c.withRateMonitor(req.Context(), &req.Metrics)
+
+			monitors[1] = hedgeMonitor
+
+			req.Metrics.Hedges++
+
+			send(hedgeCtx, 1)
+
+			pending++
+		case outcome := <-outcomes:
+			pending--
+
+			res, err = outcome.res, outcome.err
+
+			if err != nil && pending > 0 {
+				continue
+			}
+
+			// The monitor of the first attempt is settled by the caller, unless the duplicate won.
+			won := 0
+
+			if err == nil {
+				won = outcome.index
+			}
+
+			for index, cancel := range cancels {
+				if cancel == nil {
+					continue
+				}
+
+				if index != won {
+					monitors[index].settle(nil, context.Canceled)
+				}
+
+				if err != nil || index != outcome.index {
+					cancel()
+				}
+			}
+
+			if err == nil {
+				winner = monitors[won]
+
+				res.Body = &hedgedBody{ReadCloser: res.Body, cancel: cancels[won]}
+			}
+
+			if pending > 0 {
+				go func() {
+					if loser := <-outcomes; loser.res != nil {
+						loser.res.Body.Close()
+					}
+				}()
+			}
+
+			return
+		}
+	}
+
+	return
+}
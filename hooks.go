@@ -0,0 +1,62 @@
+package http
+
+import "net/http"
+
+// RequestHook defines a function type called before every attempt of a request is sent, e.g.
+// to audit requests or inject headers computed per attempt. Hooks of hedged attempts may be
+// called concurrently.
+//
+// Parameters:
+//   - req: The request.
+//   - attempt: The request of the attempt, whose header can be updated without affecting
+//     other attempts.
+//
+// Returns:
+//   - err: A non-nil error aborts the attempt with that error.
+type RequestHook func(req *Request, attempt *http.Request) (err error)
+
+// ResponseHook defines a function type called with the response of every attempt, before the
+// retry policy decides whether to retry it. The body must be left unread.
+//
+// Parameters:
+//   - req: The request.
+//   - res: The response of the attempt.
+//
+// Returns: None.
+type ResponseHook func(req *Request, res *http.Response)
+
+// AttemptErrorHook defines a function type called with the error of every failed attempt,
+// before the retry policy decides whether to retry it.
+//
+// Parameters:
+//   - req: The request.
+//   - err: The error the attempt failed with.
+//
+// Returns: None.
+type AttemptErrorHook func(req *Request, err error)
+
+// observeAttempt calls the response or error hook with the outcome of an attempt.
+//
+// Parameters:
+//   - req: The request.
+//   - res: The response of the attempt, nil if it failed.
+//   - err: The error the attempt failed with.
+//
+// Returns: None.
+func (c *Client) observeAttempt(req *Request, res *http.Response, err error) {
+	if err != nil {
+		c.logger.Warn("attempt failed", requestLogArgs(req, "error", err)...)
+
+		if c.cfg.OnAttemptError != nil {
+			c.cfg.OnAttemptError(req, err)
+		}
+
+		return
+	}
+
+	c.logger.Debug("received response", requestLogArgs(req, "status", res.StatusCode, "protocol", res.Proto)...)
+
+	if c.cfg.OnResponse != nil {
+		c.cfg.OnResponse(req, res)
+	}
+}
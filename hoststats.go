@@ -0,0 +1,150 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// hostStatsWindow is the number of most recent attempts per host the statistics cover.
+const hostStatsWindow = 100
+
+// HostStats summarizes the most recent attempts sent to a host, for schedulers and
+// dashboards deciding whether a host is healthy.
+type HostStats struct {
+	Attempts    int           // Number of attempts covered (at most the last 100).
+	Successes   int           // Attempts that got a non-5xx response.
+	Errors      int           // Attempts that failed with an error or got a 5xx response.
+	ErrorRate   float64       // Errors divided by Attempts.
+	MeanLatency time.Duration // Mean latency of the covered attempts.
+	MaxLatency  time.Duration // Maximum latency of the covered attempts.
+	LastError   time.Time     // Time of the last failed attempt, zero if none.
+}
+
+// hostOutcome is the outcome of a single attempt.
+type hostOutcome struct {
+	failed  bool
+	latency time.Duration
+}
+
+// hostWindow holds the outcomes of the most recent attempts sent to a host in a ring buffer.
+type hostWindow struct {
+	outcomes  [hostStatsWindow]hostOutcome
+	next      int
+	count     int
+	lastError time.Time
+}
+
+// hostStatsTracker keeps a hostWindow per host.
+type hostStatsTracker struct {
+	mutex   sync.Mutex
+	windows map[string]*hostWindow
+}
+
+// observe records the outcome of an attempt.
+//
+// Parameters:
+//   - host: The host the attempt was sent to.
+//   - res: The response of the attempt, nil if it failed.
+//   - err: The error the attempt failed with.
+//   - latency: The time the attempt took to get the response headers or fail.
+//
+// Returns: None.
+func (t *hostStatsTracker) observe(host string, res *http.Response, err error, latency time.Duration) {
+	failed := err != nil || res != nil && res.StatusCode >= http.StatusInternalServerError
+
+	t.mutex.Lock()
+
+	defer t.mutex.Unlock()
+
+	window, ok := t.windows[host]
+	if !ok {
+		window = &hostWindow{}
+
+		t.windows[host] = window
+	}
+
+	window.outcomes[window.next] = hostOutcome{failed: failed, latency: latency}
+	window.next = (window.next + 1) % hostStatsWindow
+	window.count = min(window.count+1, hostStatsWindow)
+
+	if failed {
+		window.lastError = time.Now()
+	}
+}
+
+// stats returns the statistics of every observed host.
+//
+// Parameters: None.
+//
+// Returns:
+//   - stats: The statistics, keyed by host.
+func (t *hostStatsTracker) stats() (stats map[string]HostStats) {
+	t.mutex.Lock()
+
+	defer t.mutex.Unlock()
+
+	stats = make(map[string]HostStats, len(t.windows))
+
+	for host, window := range t.windows {
+		var total time.Duration
+
+		hostStats := HostStats{
+			Attempts:  window.count,
+			LastError: window.lastError,
+		}
+
+		for _, outcome := range window.outcomes[:window.count] {
+			if outcome.failed {
+				hostStats.Errors++
+			} else {
+				hostStats.Successes++
+			}
+
+			total += outcome.latency
+
+			hostStats.MaxLatency = max(hostStats.MaxLatency, outcome.latency)
+		}
+
+		if window.count > 0 {
+			hostStats.ErrorRate = float64(hostStats.Errors) / float64(window.count)
+			hostStats.MeanLatency = total / time.Duration(window.count)
+		}
+
+		stats[host] = hostStats
+	}
+
+	return
+}
+
+// newHostStatsTracker creates an empty hostStatsTracker.
+//
+// Parameters: None.
+//
+// Returns:
+//   - tracker: The new tracker.
+func newHostStatsTracker() (tracker *hostStatsTracker) {
+	tracker = &hostStatsTracker{
+		windows: make(map[string]*hostWindow),
+	}
+
+	return
+}
+
+// HostStats returns rolling statistics of the attempts sent to every host, covering the last
+// 100 attempts per host. Statistics are only collected if ClientConfiguration.TrackHostStats
+// is set.
+//
+// Parameters: None.
+//
+// Returns:
+//   - stats: The statistics, keyed by host as found in the request URL (including the port, if any).
+func (c *Client) HostStats() (stats map[string]HostStats) {
+	if c.hostStats == nil {
+		return
+	}
+
+	stats = c.hostStats.stats()
+
+	return
+}
@@ -0,0 +1,250 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalidHostname is returned for hostnames that are not valid internationalized domain
+// names (IDNA 2008, UTS 46).
+var ErrInvalidHostname = errors.New("invalid hostname")
+
+// latinConfusables are the Cyrillic and Greek letters rendered like Latin letters, from which
+// whole-script homographs of Latin domains (e.g., "аррӏе" in Cyrillic) are built.
+const latinConfusables = "аеорсухіјѕһӏԁԛԝвнкмтАВЕКМНОРСТХІЈЅαεικνορτυχΑΒΕΖΗΙΚΜΝΟΡΤΥΧ"
+
+// cjkScripts are the scripts legitimately mixed with each other and with Latin in a label.
+var cjkScripts = []*unicode.RangeTable{unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul}
+
+// homographScripts are the scripts told apart by DetectHomograph. Letters of other scripts
+// count as their own script.
+var homographScripts = map[string]*unicode.RangeTable{
+	"Latin":    unicode.Latin,
+	"Cyrillic": unicode.Cyrillic,
+	"Greek":    unicode.Greek,
+	"Armenian": unicode.Armenian,
+	"Arabic":   unicode.Arabic,
+	"Hebrew":   unicode.Hebrew,
+	"Han":      unicode.Han,
+	"Hiragana": unicode.Hiragana,
+	"Katakana": unicode.Katakana,
+	"Hangul":   unicode.Hangul,
+}
+
+// ASCIIHost converts a hostname to its ASCII form, with internationalized labels as A-labels
+// (e.g., "bücher.example" to "xn--bcher-kva.example"), lowercased. IP addresses are returned
+// as is, and ASCII hostnames are only lowercased, as DNS allows names IDNA rejects (e.g.,
+// with underscores).
+//
+// Parameters:
+//   - host: The hostname, without port.
+//
+// Returns:
+//   - ascii: The ASCII form.
+//   - err: An error wrapping ErrInvalidHostname if the hostname is not a valid IDN.
+func ASCIIHost(host string) (ascii string, err error) {
+	if _, errr := netip.ParseAddr(host); errr == nil {
+		ascii = host
+
+		return
+	}
+
+	if isASCII(host) {
+		ascii = strings.ToLower(host)
+
+		return
+	}
+
+	ascii, err = idna.Lookup.ToASCII(host)
+	if err != nil {
+		err = fmt.Errorf("%w: %q: %w", ErrInvalidHostname, host, err)
+
+		return
+	}
+
+	ascii = strings.ToLower(ascii)
+
+	return
+}
+
+// UnicodeHost converts a hostname to its Unicode form, with A-labels decoded (e.g.,
+// "xn--bcher-kva.example" to "bücher.example"), e.g. to display it.
+//
+// Parameters:
+//   - host: The hostname, without port.
+//
+// Returns:
+//   - unicodeHost: The Unicode form.
+//   - err: An error wrapping ErrInvalidHostname if the hostname is not a valid IDN.
+func UnicodeHost(host string) (unicodeHost string, err error) {
+	unicodeHost, err = idna.Display.ToUnicode(host)
+	if err != nil {
+		err = fmt.Errorf("%w: %q: %w", ErrInvalidHostname, host, err)
+	}
+
+	return
+}
+
+// DetectHomograph flags hostnames likely to impersonate another domain: labels mixing scripts
+// (e.g., Latin and Cyrillic), other than Latin with Chinese, Japanese and Korean scripts, and
+// labels written entirely with Cyrillic or Greek letters rendered like Latin letters.
+//
+// Parameters:
+//   - host: The hostname, in ASCII or Unicode form, without port.
+//
+// Returns:
+//   - suspicious: Whether the hostname is likely a homograph.
+//   - reason: Why it is, empty if it is not.
+func DetectHomograph(host string) (suspicious bool, reason string) {
+	unicodeHost, err := UnicodeHost(host)
+	if err != nil {
+		return
+	}
+
+	for _, label := range strings.Split(unicodeHost, ".") {
+		scripts := map[string]bool{}
+		confusable := true
+		letters := 0
+
+		for _, r := range label {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+
+			letters++
+
+			scripts[scriptOf(r)] = true
+
+			if !strings.ContainsRune(latinConfusables, r) {
+				confusable = false
+			}
+		}
+
+		if letters == 0 {
+			continue
+		}
+
+		if len(scripts) > 1 && !isAllowedScriptMix(scripts) {
+			suspicious, reason = true, fmt.Sprintf("label %q mixes scripts", label)
+
+			return
+		}
+
+		if confusable {
+			suspicious, reason = true, fmt.Sprintf("label %q only uses letters confusable with Latin", label)
+
+			return
+		}
+	}
+
+	return
+}
+
+// scriptOf returns the script of a letter.
+//
+// Parameters:
+//   - r: The letter.
+//
+// Returns:
+//   - script: The name of its script, "Other" if it is none of homographScripts.
+func scriptOf(r rune) (script string) {
+	for name, table := range homographScripts {
+		if unicode.Is(table, r) {
+			script = name
+
+			return
+		}
+	}
+
+	script = "Other"
+
+	return
+}
+
+// isAllowedScriptMix reports whether the scripts of a label are a legitimate mix: Chinese,
+// Japanese and Korean scripts, optionally with Latin.
+//
+// Parameters:
+//   - scripts: The scripts of the letters of the label.
+//
+// Returns:
+//   - allowed: Whether the mix is allowed.
+func isAllowedScriptMix(scripts map[string]bool) (allowed bool) {
+	for script := range scripts {
+		if script == "Latin" {
+			continue
+		}
+
+		if table, ok := homographScripts[script]; !ok || !slices.Contains(cjkScripts, table) {
+			return
+		}
+	}
+
+	allowed = true
+
+	return
+}
+
+// isASCII reports whether a string only contains ASCII characters.
+//
+// Parameters:
+//   - s: The string.
+//
+// Returns:
+//   - is: Whether it does.
+func isASCII(s string) (is bool) {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf {
+			return
+		}
+	}
+
+	is = true
+
+	return
+}
+
+// canonicalizeHost converts an internationalized hostname of a request URL to its ASCII form
+// (see ASCIIHost), so that it is dialed, matched against per host settings and checked against
+// destination restrictions in a single form. ASCII hostnames are left untouched.
+//
+// Parameters:
+//   - req: The request to update.
+//
+// Returns:
+//   - err: An error wrapping ErrInvalidHostname if the hostname is not a valid IDN.
+func canonicalizeHost(req *Request) (err error) {
+	hostname := req.URL.Hostname()
+
+	if isASCII(hostname) {
+		return
+	}
+
+	ascii, err := ASCIIHost(hostname)
+	if err != nil {
+		return
+	}
+
+	original := req.URL.Host
+
+	if port := req.URL.Port(); port != "" {
+		req.URL.Host = net.JoinHostPort(ascii, port)
+	} else {
+		req.URL.Host = ascii
+	}
+
+	// A Host header left to default to the URL host follows it.
+	if req.Host == original {
+		req.Host = req.URL.Host
+	}
+
+	return
+}
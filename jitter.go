@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter defines how retry delays are randomized, independently of the backoff strategy, so
+// that many clients retrying at once (e.g., a high-concurrency scan) spread their retries
+// instead of hitting the server in waves.
+type Jitter string
+
+const (
+	// JitterNone uses the delays of the backoff strategy as is.
+	JitterNone Jitter = ""
+	// JitterFull picks a delay between zero and the backoff delay.
+	JitterFull Jitter = "full"
+	// JitterEqual picks a delay between half the backoff delay and the backoff delay.
+	JitterEqual Jitter = "equal"
+	// JitterDecorrelated picks a delay between the minimum delay and three times the previous
+	// delay, capped to the maximum delay, ignoring the backoff strategy.
+	JitterDecorrelated Jitter = "decorrelated"
+)
+
+// RetryJitter overrides ClientConfiguration.RetryJitter for a request when set to a Jitter on
+// its context (see RequestBuilder.RetryJitter).
+const RetryJitter ContextOverride = "retry-jitter"
+
+// apply randomizes a retry delay.
+//
+// Parameters:
+//   - delay: The delay computed by the backoff strategy.
+//   - minDelay: The minimum delay between retries.
+//   - maxDelay: The maximum delay between retries.
+//   - previous: The previous delay, used by JitterDecorrelated (minDelay for the first retry).
+//
+// Returns:
+//   - jittered: The randomized delay.
+func (j Jitter) apply(delay, minDelay, maxDelay, previous time.Duration) (jittered time.Duration) {
+	jittered = delay
+
+	switch j {
+	case JitterFull:
+		jittered = randomDuration(0, delay)
+	case JitterEqual:
+		jittered = delay/2 + randomDuration(0, delay-delay/2)
+	case JitterDecorrelated:
+		jittered = randomDuration(minDelay, max(minDelay, previous)*3)
+
+		if maxDelay > 0 {
+			jittered = min(jittered, maxDelay)
+		}
+	case JitterNone:
+	}
+
+	return
+}
+
+// randomDuration returns a random duration in [low, high].
+//
+// Parameters:
+//   - low: The lower bound.
+//   - high: The upper bound.
+//
+// Returns:
+//   - duration: The random duration, low if high is not above it.
+func randomDuration(low, high time.Duration) (duration time.Duration) {
+	duration = low
+
+	if high > low {
+		duration += rand.N(high - low + 1) //nolint:gosec // Retry delays need no cryptographic randomness.
+	}
+
+	return
+}
+
+// requestJitter returns the jitter a request context overrides ClientConfiguration.RetryJitter with.
+//
+// Parameters:
+//   - ctx: The request context.
+//
+// Returns:
+//   - jitter: The jitter.
+//   - ok: Whether the context carries a RetryJitter override.
+func requestJitter(ctx context.Context) (jitter Jitter, ok bool) {
+	jitter, ok = ctx.Value(RetryJitter).(Jitter)
+
+	return
+}
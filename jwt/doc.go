@@ -0,0 +1,5 @@
+// Package jwt provides a minimal implementation of JSON Web Tokens (RFC 7519) signed with
+// HS256, RS256 or ES256 (RFC 7518). It covers what HTTP clients commonly need without a
+// separate dependency: minting client assertions for OAuth 2.0 private_key_jwt client
+// authentication (RFC 7523) and validating or inspecting tokens returned by servers.
+package jwt
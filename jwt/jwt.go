@@ -0,0 +1,455 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Algorithm represents a JWS signing algorithm as defined by RFC 7518, section 3.1.
+type Algorithm string
+
+func (a Algorithm) String() (algorithm string) {
+	return string(a)
+}
+
+const (
+	HS256 Algorithm = "HS256" // HMAC using SHA-256. The key is a []byte.
+	RS256 Algorithm = "RS256" // RSASSA-PKCS1-v1_5 using SHA-256. The key is an *rsa.PrivateKey or *rsa.PublicKey.
+	ES256 Algorithm = "ES256" // ECDSA using P-256 and SHA-256. The key is an *ecdsa.PrivateKey or *ecdsa.PublicKey.
+)
+
+var (
+	// ErrMalformed is returned when a token is not made of three base64url encoded parts.
+	ErrMalformed = errors.New("malformed token")
+	// ErrUnsupportedAlgorithm is returned for algorithms other than HS256, RS256 and ES256,
+	// including "none".
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	// ErrInvalidKey is returned when a key does not match the algorithm.
+	ErrInvalidKey = errors.New("invalid key for algorithm")
+	// ErrInvalidSignature is returned when the signature of a token does not verify.
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrExpired is returned when a token is past its "exp" claim.
+	ErrExpired = errors.New("token expired")
+	// ErrNotYetValid is returned when a token is before its "nbf" claim.
+	ErrNotYetValid = errors.New("token not yet valid")
+)
+
+// Header is the JOSE header of a token.
+type Header struct {
+	Algorithm Algorithm              `json:"alg"`
+	Type      string                 `json:"typ,omitempty"`
+	KeyID     string                 `json:"kid,omitempty"`
+	JWK       map[string]interface{} `json:"jwk,omitempty"` // Public key the token is signed with (e.g., in DPoP proofs).
+}
+
+// Claims is the claims set of a token. Numeric claims decoded from a token are float64.
+type Claims map[string]interface{}
+
+// Time returns a NumericDate claim (e.g., "exp", "iat", "nbf") as a time.
+//
+// Parameters:
+//   - name: The claim name.
+//
+// Returns:
+//   - t: The claim as a time.
+//   - ok: Whether the claim is present and numeric.
+func (c Claims) Time(name string) (t time.Time, ok bool) {
+	var seconds float64
+
+	switch value := c[name].(type) {
+	case float64:
+		seconds = value
+	case int64:
+		seconds = float64(value)
+	case int:
+		seconds = float64(value)
+	default:
+		return
+	}
+
+	t, ok = time.Unix(0, int64(seconds*float64(time.Second))), true
+
+	return
+}
+
+// Sign mints a token carrying the claims, signed with the algorithm and key.
+//
+// Parameters:
+//   - algorithm: The signing algorithm.
+//   - key: The signing key: a []byte for HS256, an *rsa.PrivateKey for RS256 and an
+//     *ecdsa.PrivateKey (P-256) for ES256.
+//   - keyID: The "kid" header parameter, omitted if empty.
+//   - claims: The claims set.
+//
+// Returns:
+//   - token: The compact serialization of the token.
+//   - err: An error if the key does not match the algorithm or signing fails.
+func Sign(algorithm Algorithm, key interface{}, keyID string, claims Claims) (token string, err error) {
+	token, err = SignWithHeader(Header{Algorithm: algorithm, Type: "JWT", KeyID: keyID}, key, claims)
+
+	return
+}
+
+// SignWithHeader mints a token carrying the claims with a custom JOSE header, for token types
+// other than plain JWTs (e.g., "dpop+jwt"). The token is signed with header.Algorithm.
+//
+// Parameters:
+//   - header: The JOSE header.
+//   - key: The signing key, as for Sign.
+//   - claims: The claims set.
+//
+// Returns:
+//   - token: The compact serialization of the token.
+//   - err: An error if the key does not match the algorithm or signing fails.
+func SignWithHeader(header Header, key interface{}, claims Claims) (token string, err error) {
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return
+	}
+
+	signingInput := encode(encodedHeader) + "." + encode(payload)
+
+	signature, err := sign(header.Algorithm, key, signingInput)
+	if err != nil {
+		return
+	}
+
+	token = signingInput + "." + encode(signature)
+
+	return
+}
+
+// Parse decodes a token without verifying its signature, to inspect it (e.g., read the "kid"
+// header parameter to pick the verification key). Never trust claims returned by Parse.
+//
+// Parameters:
+//   - token: The compact serialization of the token.
+//
+// Returns:
+//   - header: The JOSE header.
+//   - claims: The claims set.
+//   - err: An error wrapping ErrMalformed if the token cannot be decoded.
+func Parse(token string) (header Header, claims Claims, err error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		err = fmt.Errorf("%w: expected 3 parts, got %d", ErrMalformed, len(parts))
+
+		return
+	}
+
+	if err = decodeJSON(parts[0], &header); err != nil {
+		return
+	}
+
+	err = decodeJSON(parts[1], &claims)
+
+	return
+}
+
+// Verify verifies the signature of a token with the expected algorithm and key, then checks
+// its "exp" and "nbf" claims against the current time, tolerating the given leeway.
+// The algorithm of the token must match the expected one, so that a token cannot pick a
+// weaker algorithm (e.g., HS256 with an RSA public key as secret).
+//
+// Parameters:
+//   - token: The compact serialization of the token.
+//   - algorithm: The expected signing algorithm.
+//   - key: The verification key: a []byte for HS256, an *rsa.PublicKey for RS256 and an
+//     *ecdsa.PublicKey for ES256. Private keys are accepted as well.
+//   - leeway: The clock skew tolerated on "exp" and "nbf".
+//
+// Returns:
+//   - claims: The verified claims set, nil if err is not nil.
+//   - err: An error if the token is malformed, its signature does not verify or it is not
+//     valid at the current time.
+func Verify(token string, algorithm Algorithm, key interface{}, leeway time.Duration) (claims Claims, err error) {
+	header, parsed, err := Parse(token)
+	if err != nil {
+		return
+	}
+
+	if header.Algorithm != algorithm {
+		err = fmt.Errorf("%w: %q, expected %q", ErrUnsupportedAlgorithm, header.Algorithm, algorithm)
+
+		return
+	}
+
+	index := strings.LastIndexByte(token, '.')
+
+	signature, err := base64.RawURLEncoding.DecodeString(token[index+1:])
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrMalformed, err)
+
+		return
+	}
+
+	if err = verify(algorithm, key, token[:index], signature); err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	if expiry, ok := parsed.Time("exp"); ok && now.After(expiry.Add(leeway)) {
+		err = ErrExpired
+
+		return
+	}
+
+	if notBefore, ok := parsed.Time("nbf"); ok && now.Before(notBefore.Add(-leeway)) {
+		err = ErrNotYetValid
+
+		return
+	}
+
+	claims = parsed
+
+	return
+}
+
+// NewClientAssertion builds the claims of a client assertion for OAuth 2.0 private_key_jwt
+// client authentication (RFC 7523, section 3): the client is both issuer and subject, the
+// audience is the token endpoint, and a random "jti" prevents replays.
+//
+// Parameters:
+//   - clientID: The OAuth 2.0 client identifier.
+//   - audience: The token endpoint URL.
+//   - lifetime: The validity of the assertion, typically a few minutes.
+//
+// Returns:
+//   - claims: The claims set, to be signed with Sign.
+//   - err: An error if the random "jti" cannot be generated.
+func NewClientAssertion(clientID, audience string, lifetime time.Duration) (claims Claims, err error) {
+	jti := make([]byte, 16)
+
+	if _, err = rand.Read(jti); err != nil {
+		return
+	}
+
+	now := time.Now()
+
+	claims = Claims{
+		"iss": clientID,
+		"sub": clientID,
+		"aud": audience,
+		"jti": hex.EncodeToString(jti),
+		"iat": now.Unix(),
+		"exp": now.Add(lifetime).Unix(),
+	}
+
+	return
+}
+
+// sign computes the signature of the signing input.
+//
+// Parameters:
+//   - algorithm: The signing algorithm.
+//   - key: The signing key.
+//   - signingInput: The encoded header and payload, joined by a dot.
+//
+// Returns:
+//   - signature: The signature.
+//   - err: An error if the key does not match the algorithm or signing fails.
+func sign(algorithm Algorithm, key interface{}, signingInput string) (signature []byte, err error) {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch algorithm {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			err = fmt.Errorf("%w: %s requires a []byte", ErrInvalidKey, algorithm)
+
+			return
+		}
+
+		mac := hmac.New(sha256.New, secret)
+
+		mac.Write([]byte(signingInput))
+
+		signature = mac.Sum(nil)
+	case RS256:
+		private, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			err = fmt.Errorf("%w: %s requires an *rsa.PrivateKey", ErrInvalidKey, algorithm)
+
+			return
+		}
+
+		signature, err = rsa.SignPKCS1v15(rand.Reader, private, crypto.SHA256, digest[:])
+	case ES256:
+		private, ok := key.(*ecdsa.PrivateKey)
+		if !ok || private.Curve.Params().BitSize != 256 {
+			err = fmt.Errorf("%w: %s requires a P-256 *ecdsa.PrivateKey", ErrInvalidKey, algorithm)
+
+			return
+		}
+
+		var r, s *big.Int
+
+		r, s, err = ecdsa.Sign(rand.Reader, private, digest[:])
+		if err != nil {
+			return
+		}
+
+		// JWS uses the fixed size concatenation of R and S rather than ASN.1 (RFC 7518, section 3.4).
+		signature = make([]byte, 64)
+
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, algorithm)
+	}
+
+	return
+}
+
+// verify verifies the signature of the signing input.
+//
+// Parameters:
+//   - algorithm: The signing algorithm.
+//   - key: The verification key.
+//   - signingInput: The encoded header and payload, joined by a dot.
+//   - signature: The signature to verify.
+//
+// Returns:
+//   - err: An error wrapping ErrInvalidSignature if the signature does not verify, or
+//     ErrInvalidKey if the key does not match the algorithm.
+func verify(algorithm Algorithm, key interface{}, signingInput string, signature []byte) (err error) {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	valid := false
+
+	switch algorithm {
+	case HS256:
+		var expected []byte
+
+		expected, err = sign(algorithm, key, signingInput)
+		if err != nil {
+			return
+		}
+
+		valid = hmac.Equal(signature, expected)
+	case RS256:
+		public, ok := key.(*rsa.PublicKey)
+
+		if private, isPrivate := key.(*rsa.PrivateKey); isPrivate {
+			public, ok = &private.PublicKey, true
+		}
+
+		if !ok {
+			err = fmt.Errorf("%w: %s requires an *rsa.PublicKey", ErrInvalidKey, algorithm)
+
+			return
+		}
+
+		valid = rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature) == nil
+	case ES256:
+		public, ok := key.(*ecdsa.PublicKey)
+
+		if private, isPrivate := key.(*ecdsa.PrivateKey); isPrivate {
+			public, ok = &private.PublicKey, true
+		}
+
+		if !ok {
+			err = fmt.Errorf("%w: %s requires an *ecdsa.PublicKey", ErrInvalidKey, algorithm)
+
+			return
+		}
+
+		if len(signature) == 64 {
+			r := new(big.Int).SetBytes(signature[:32])
+			s := new(big.Int).SetBytes(signature[32:])
+
+			valid = ecdsa.Verify(public, digest[:], r, s)
+		}
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, algorithm)
+
+		return
+	}
+
+	if !valid {
+		err = ErrInvalidSignature
+	}
+
+	return
+}
+
+// PublicJWK returns the public JSON Web Key (RFC 7517) of a P-256 ECDSA key.
+//
+// Parameters:
+//   - key: The public key.
+//
+// Returns:
+//   - jwk: The JWK members ("kty", "crv", "x" and "y").
+func PublicJWK(key *ecdsa.PublicKey) (jwk map[string]interface{}) {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+
+	key.X.FillBytes(x)
+	key.Y.FillBytes(y)
+
+	jwk = map[string]interface{}{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   encode(x),
+		"y":   encode(y),
+	}
+
+	return
+}
+
+// encode encodes data with the unpadded base64url encoding used by JWS.
+//
+// Parameters:
+//   - data: The data to encode.
+//
+// Returns:
+//   - encoded: The encoded data.
+func encode(data []byte) (encoded string) {
+	encoded = base64.RawURLEncoding.EncodeToString(data)
+
+	return
+}
+
+// decodeJSON decodes a base64url encoded JSON part of a token.
+//
+// Parameters:
+//   - part: The encoded part.
+//   - target: The value to decode into.
+//
+// Returns:
+//   - err: An error wrapping ErrMalformed if the part cannot be decoded.
+func decodeJSON(part string, target interface{}) (err error) {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrMalformed, err)
+
+		return
+	}
+
+	if err = json.Unmarshal(data, target); err != nil {
+		err = fmt.Errorf("%w: %w", ErrMalformed, err)
+
+		return
+	}
+
+	return
+}
@@ -0,0 +1,88 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	key := []byte("secret")
+
+	now := time.Now()
+
+	sign := func(t *testing.T, algorithm Algorithm, claims Claims) (token string) {
+		t.Helper()
+
+		token, err := Sign(algorithm, key, "", claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		key     []byte
+		wantErr error
+	}{
+		{
+			name: "valid token",
+			token: func(t *testing.T) string {
+				return sign(t, HS256, Claims{"sub": "user", "exp": now.Add(time.Hour).Unix()})
+			},
+			key: key,
+		},
+		{
+			name: "invalid signature",
+			token: func(t *testing.T) string {
+				return sign(t, HS256, Claims{"sub": "user"})
+			},
+			key:     []byte("other"),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "expired token",
+			token: func(t *testing.T) string {
+				return sign(t, HS256, Claims{"sub": "user", "exp": now.Add(-time.Hour).Unix()})
+			},
+			key:     key,
+			wantErr: ErrExpired,
+		},
+		{
+			name: "token not yet valid",
+			token: func(t *testing.T) string {
+				return sign(t, HS256, Claims{"sub": "user", "nbf": now.Add(time.Hour).Unix()})
+			},
+			key:     key,
+			wantErr: ErrNotYetValid,
+		},
+		{
+			name: "malformed token",
+			token: func(*testing.T) string {
+				return "header.claims"
+			},
+			key:     key,
+			wantErr: ErrMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := Verify(tt.token(t), HS256, tt.key, 0)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil && claims != nil {
+				t.Fatalf("got claims %v with error %v, want none", claims, err)
+			}
+
+			if err == nil && claims["sub"] != "user" {
+				t.Fatalf("got claims %v, want sub %q", claims, "user")
+			}
+		})
+	}
+}
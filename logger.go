@@ -0,0 +1,100 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// Logger defines the structured logger the client logs attempts, retries and fallbacks to.
+// Fields are given as alternating keys and values, so that a *slog.Logger can be used as is.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// nopLogger is the Logger used when ClientConfiguration.Logger is not set.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Info(string, ...any)  {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
+
+// SensitiveHeaders are the headers whose values are redacted from logs and, when requested,
+// from HAR archives and curl commands.
+var SensitiveHeaders = []string{
+	headers.Authorization.String(),
+	headers.ProxyAuthorization.String(),
+	headers.Cookie.String(),
+	headers.SetCookie.String(),
+	headers.DPoP.String(),
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// redactedValue replaces the values of sensitive headers in logs.
+const redactedValue = "[REDACTED]"
+
+// redactHeader returns a copy of a header with the values of SensitiveHeaders redacted.
+//
+// Parameters:
+//   - header: The header to redact.
+//
+// Returns:
+//   - redacted: The redacted copy.
+func redactHeader(header http.Header) (redacted http.Header) {
+	redacted = header.Clone()
+
+	for key, values := range redacted {
+		if !slices.ContainsFunc(SensitiveHeaders, func(sensitive string) bool {
+			return strings.EqualFold(sensitive, key)
+		}) {
+			continue
+		}
+
+		for i := range values {
+			values[i] = redactedValue
+		}
+	}
+
+	return
+}
+
+// logAttempt logs an attempt about to be sent, with its sensitive headers redacted.
+//
+// Parameters:
+//   - attempt: The request of the attempt.
+//
+// Returns: None.
+func (c *Client) logAttempt(attempt *http.Request) {
+	if c.cfg.Logger == nil {
+		return
+	}
+
+	c.logger.Debug("sending attempt", "method", attempt.Method, "url", redactedURL(attempt.URL), "header", redactHeader(attempt.Header))
+}
+
+// requestLogArgs returns the attributes identifying a request in logs, followed by args.
+//
+// Parameters:
+//   - req: The request.
+//   - args: The attributes of the log entry.
+//
+// Returns:
+//   - attributes: The method, URL and, if set, ID of the request, followed by args.
+func requestLogArgs(req *Request, args ...any) (attributes []any) {
+	attributes = []any{"method", req.Method, "url", redactedURL(req.URL)}
+
+	if req.Metrics.RequestID != "" {
+		attributes = append(attributes, "request_id", req.Metrics.RequestID)
+	}
+
+	attributes = append(attributes, args...)
+
+	return
+}
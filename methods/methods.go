@@ -1,5 +1,11 @@
 package methods
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // Method represents HTTP methods as defined by IANA.
 // Reference: https://www.iana.org/assignments/http-methods/http-methods.xhtml
 type Method string
@@ -55,3 +61,59 @@ const (
 	// Defined in RFC 7231, section 4.3.8.
 	Trace Method = "TRACE" // RFC 7231, 4.3.8
 )
+
+// ErrInvalidMethod is returned by Parse when the given string is not a valid HTTP method token.
+var ErrInvalidMethod = errors.New("invalid HTTP method")
+
+// known maps the upper-cased names of the methods defined in this package to their constants.
+var known = map[string]Method{
+	Connect.String(): Connect,
+	Delete.String():  Delete,
+	Get.String():     Get,
+	Head.String():    Head,
+	Options.String(): Options,
+	Patch.String():   Patch,
+	Post.String():    Post,
+	Put.String():     Put,
+	Trace.String():   Trace,
+}
+
+// Parse converts a string into a Method. The standard methods are matched case-insensitively
+// and returned as their constants. Any other string is accepted as an extension method as
+// long as it is a valid token (RFC 9110, section 9.1), and is returned unchanged since
+// extension methods are case-sensitive.
+//
+// Parameters:
+//   - method: The method name (e.g., "get", "PROPFIND").
+//
+// Returns:
+//   - parsed: The parsed Method.
+//   - err: An error wrapping ErrInvalidMethod if method is empty or not a valid token.
+func Parse(method string) (parsed Method, err error) {
+	if m, ok := known[strings.ToUpper(method)]; ok {
+		parsed = m
+
+		return
+	}
+
+	if method == "" || strings.IndexFunc(method, isNotTokenChar) >= 0 {
+		err = fmt.Errorf("%w: %q", ErrInvalidMethod, method)
+
+		return
+	}
+
+	parsed = Method(method)
+
+	return
+}
+
+// isNotTokenChar reports whether r is not allowed in an HTTP token (RFC 9110, section 5.6.2).
+func isNotTokenChar(r rune) (is bool) {
+	if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+		return
+	}
+
+	is = !strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+
+	return
+}
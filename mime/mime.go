@@ -26,6 +26,7 @@ const (
 	CSV                      MIME = "text/csv"
 	CShellScript             MIME = "application/x-csh"
 	EPUB                     MIME = "application/epub+zip"
+	FormURLEncoded           MIME = "application/x-www-form-urlencoded"
 	GIF                      MIME = "image/gif"
 	GZipCompressedArchive    MIME = "application/gzip"
 	HTML                     MIME = "text/html"
@@ -50,6 +51,10 @@ const (
 	MSVisio                  MIME = "application/vnd.visio"
 	MSWord                   MIME = "application/msword"
 	MSWordOpenXML            MIME = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MultipartByteRanges      MIME = "multipart/byteranges"
+	MultipartFormData        MIME = "multipart/form-data"
+	MultipartMixed           MIME = "multipart/mixed"
+	MultipartRelated         MIME = "multipart/related"
 	OGG                      MIME = "application/ogg"
 	OGGAudio                 MIME = "audio/ogg"
 	OGGVideo                 MIME = "video/ogg"
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// ErrNotMultipart is returned by NewMultipartReader for responses that are not multipart or
+// lack a boundary.
+var ErrNotMultipart = errors.New("response is not multipart")
+
+// ResponsePart is a part of a multipart response.
+type ResponsePart struct {
+	Header http.Header // Header of the part (e.g., Content-Type, Content-Range for multipart/byteranges).
+	Body   io.Reader   // Body of the part, valid until the next part is read.
+}
+
+// MultipartReader iterates the parts of a multipart response (e.g., multipart/byteranges
+// responses to multi-range requests, multipart/mixed responses of batch APIs) as streams,
+// without buffering the response.
+type MultipartReader struct {
+	MediaType string // Media type of the response (e.g., "multipart/byteranges").
+
+	reader *multipart.Reader
+	body   io.Closer
+}
+
+// Next returns the next part of the response. The body of the previous part is discarded.
+//
+// Parameters: None.
+//
+// Returns:
+//   - part: The next part.
+//   - err: io.EOF once every part was read, or an error if the response is malformed.
+func (r *MultipartReader) Next() (part *ResponsePart, err error) {
+	raw, err := r.reader.NextRawPart()
+	if err != nil {
+		return
+	}
+
+	part = &ResponsePart{
+		Header: http.Header(raw.Header),
+		Body:   raw,
+	}
+
+	return
+}
+
+// Close closes the response body.
+//
+// Parameters: None.
+//
+// Returns:
+//   - err: The error closing the body.
+func (r *MultipartReader) Close() (err error) {
+	err = r.body.Close()
+
+	return
+}
+
+// NewMultipartReader creates a MultipartReader reading the parts of a response. Part bodies
+// are returned as sent, Content-Transfer-Encoding included.
+//
+// Parameters:
+//   - res: The response, whose Content-Type must be a multipart media type with a boundary.
+//
+// Returns:
+//   - reader: The new reader, owning the response body.
+//   - err: An error wrapping ErrNotMultipart if the response is not multipart.
+func NewMultipartReader(res *http.Response) (reader *MultipartReader, err error) {
+	mediaType, params, err := mime.ParseMediaType(res.Header.Get(headers.ContentType.String()))
+	if err != nil {
+		err = fmt.Errorf("%w: %w", ErrNotMultipart, err)
+
+		return
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		err = fmt.Errorf("%w: %s", ErrNotMultipart, mediaType)
+
+		return
+	}
+
+	reader = &MultipartReader{
+		MediaType: mediaType,
+		reader:    multipart.NewReader(res.Body, params["boundary"]),
+		body:      res.Body,
+	}
+
+	return
+}
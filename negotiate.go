@@ -0,0 +1,152 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// ErrNegotiate is returned when a server answering a Negotiate authenticated request cannot be
+// authenticated back (see NegotiateVerifier).
+var ErrNegotiate = errors.New("negotiate authentication failed")
+
+// negotiateScheme is the HTTP authentication scheme of SPNEGO (RFC 4559).
+const negotiateScheme = "Negotiate"
+
+// NegotiateProvider is the GSSAPI credential source answering Negotiate challenges (SPNEGO,
+// RFC 4559), set through ClientConfiguration.Negotiate, e.g. a Kerberos client built on a
+// keytab or credential cache. It is registered after ClientConfiguration.AuthSchemes.
+// Implementations must be safe for concurrent use.
+//
+// Only single round trip mechanisms, such as Kerberos, are supported: the client answers a
+// challenge once, on whatever connection the transport picks.
+type NegotiateProvider interface {
+	// InitSecContext returns the initial token of a security context with a service
+	// principal (e.g., "HTTP/intranet.example").
+	InitSecContext(ctx context.Context, service string) (token []byte, err error)
+}
+
+// NegotiateVerifier is implemented by NegotiateProviders authenticating servers back (mutual
+// authentication): the token a server answers an authenticated request with is verified, and
+// the request fails with ErrNegotiate if it is invalid.
+type NegotiateVerifier interface {
+	// VerifySecContext verifies the token sent back by a service principal.
+	VerifySecContext(ctx context.Context, service string, token []byte) (err error)
+}
+
+// negotiateChallenge looks for a Negotiate challenge in the WWW-Authenticate headers of a
+// response.
+//
+// Parameters:
+//   - res: The response.
+//
+// Returns:
+//   - token: The decoded token of the challenge, empty for an initial challenge.
+//   - ok: Whether the response carries a Negotiate challenge.
+func negotiateChallenge(res *http.Response) (token []byte, ok bool) {
+	for _, challenge := range headers.SplitListField(res.Header.Values(headers.WWWAuthenticate.String())...) {
+		scheme, value, _ := strings.Cut(challenge, " ")
+
+		if !strings.EqualFold(scheme, negotiateScheme) {
+			continue
+		}
+
+		ok = true
+
+		token, _ = base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+
+		return
+	}
+
+	return
+}
+
+// negotiateService returns the service principal of the host of a request, in the
+// "HTTP/<host>" form of Kerberos service principal names.
+//
+// Parameters:
+//   - attempt: The request of the attempt.
+//
+// Returns:
+//   - service: The service principal.
+func negotiateService(attempt *http.Request) (service string) {
+	service = "HTTP/" + strings.ToLower(attempt.URL.Hostname())
+
+	return
+}
+
+// negotiateAuthScheme is the AuthScheme answering Negotiate challenges with the
+// NegotiateProvider of ClientConfiguration.Negotiate.
+type negotiateAuthScheme struct {
+	provider NegotiateProvider
+}
+
+// Scheme returns "Negotiate".
+func (s *negotiateAuthScheme) Scheme() (scheme string) {
+	scheme = negotiateScheme
+
+	return
+}
+
+// Authorize returns the initial token of a security context with the service principal of
+// the host of an attempt.
+//
+// Parameters:
+//   - ctx: The context of the attempt.
+//   - attempt: The request of the attempt.
+//   - challenge: The Negotiate challenge.
+//
+// Returns:
+//   - credentials: The Negotiate credentials.
+//   - err: The error of the credential source.
+func (s *negotiateAuthScheme) Authorize(ctx context.Context, attempt *http.Request, _ headers.Challenge) (credentials string, err error) {
+	service := negotiateService(attempt)
+
+	token, err := s.provider.InitSecContext(ctx, service)
+	if err != nil {
+		err = fmt.Errorf("%s: %w", service, err)
+
+		return
+	}
+
+	credentials = negotiateScheme + " " + base64.StdEncoding.EncodeToString(token)
+
+	return
+}
+
+// verifyNegotiation verifies the token a server answered a Negotiate authenticated attempt
+// with, if the configured NegotiateProvider implements NegotiateVerifier.
+//
+// Parameters:
+//   - attempt: The request of the attempt.
+//   - res: The response of the attempt.
+//   - answer: The credentials the attempt answered a challenge with.
+//
+// Returns:
+//   - err: An error wrapping ErrNegotiate if the server cannot be authenticated.
+func (c *Client) verifyNegotiation(attempt *http.Request, res *http.Response, answer *challengeAnswer) (err error) {
+	verifier, ok := c.cfg.Negotiate.(NegotiateVerifier)
+	if !ok || answer.header != headers.Authorization || !strings.EqualFold(answer.scheme, negotiateScheme) || res.StatusCode == http.StatusUnauthorized {
+		return
+	}
+
+	service := negotiateService(attempt)
+
+	token, ok := negotiateChallenge(res)
+	if !ok || len(token) == 0 {
+		err = fmt.Errorf("%w: %s: no token sent back", ErrNegotiate, service)
+
+		return
+	}
+
+	if err = verifier.VerifySecContext(attempt.Context(), service, token); err != nil {
+		err = fmt.Errorf("%w: %s: %w", ErrNegotiate, service, err)
+	}
+
+	return
+}
@@ -0,0 +1,7 @@
+//go:build !race
+
+package http
+
+// raceEnabled reports whether the race detector is enabled, which allocates on its own and
+// voids allocation budgets.
+const raceEnabled = false
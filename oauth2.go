@@ -0,0 +1,177 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.source.hueristiq.com/http/headers"
+	"go.source.hueristiq.com/http/jwt"
+	"go.source.hueristiq.com/http/mime"
+)
+
+const (
+	// DefaultTokenRefreshMargin is how long before their expiry client credentials tokens are
+	// refreshed when ClientCredentials.RefreshMargin is zero.
+	DefaultTokenRefreshMargin = 30 * time.Second
+
+	// clientAssertionType is the client_assertion_type of JWT client assertions (RFC 7523).
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	// clientAssertionLifetime is the validity of JWT client assertions.
+	clientAssertionLifetime = 5 * time.Minute
+)
+
+// ErrTokenEndpoint is returned when a token endpoint rejects a token request or answers
+// without an access token.
+var ErrTokenEndpoint = errors.New("token endpoint error")
+
+// ClientCredentials configures the OAuth 2.0 client credentials grant (RFC 6749, section 4.4).
+// The client authenticates with its secret (client_secret_basic) or, if AssertionKey is set,
+// with a signed JWT (private_key_jwt, RFC 7523).
+type ClientCredentials struct {
+	TokenURL     string // URL of the token endpoint.
+	ClientID     string // Client identifier.
+	ClientSecret string // Client secret, sent with HTTP Basic authentication.
+
+	AssertionAlgorithm jwt.Algorithm // Algorithm of the client assertion (e.g., jwt.RS256).
+	AssertionKey       interface{}   // Private key signing the client assertion, in place of the secret. Nil uses the secret.
+	AssertionKeyID     string        // Key ID ("kid") of the client assertion, empty if none.
+
+	Scopes         []string   // Scopes requested, none if empty.
+	EndpointParams url.Values // Additional parameters of token requests (e.g., audience, resource).
+
+	RefreshMargin time.Duration // How long before its expiry a token is refreshed, DefaultTokenRefreshMargin if zero.
+}
+
+// clientCredentialsResponse is the JSON response of a token endpoint (RFC 6749, section 5).
+type clientCredentialsResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetch requests a new token from the token endpoint.
+//
+// Parameters:
+//   - ctx: The context of the token request.
+//   - client: The client sending the token request.
+//
+// Returns:
+//   - token: The access token.
+//   - expiry: When the token expires, zero if the endpoint did not say.
+//   - err: An error wrapping ErrTokenEndpoint if the endpoint rejected the request, or the
+//     error of the request.
+func (c *ClientCredentials) fetch(ctx context.Context, client *Client) (token string, expiry time.Time, err error) {
+	form := url.Values{}
+
+	for key, values := range c.EndpointParams {
+		form[key] = values
+	}
+
+	form.Set("grant_type", "client_credentials")
+
+	if len(c.Scopes) > 0 {
+		form.Set("scope", strings.Join(c.Scopes, " "))
+	}
+
+	builder := client.POST(c.TokenURL).
+		Context(ctx).
+		SetHeader(headers.ContentType.String(), mime.FormURLEncoded.String()).
+		SetHeader(headers.Accept.String(), mime.JSON.String())
+
+	if c.AssertionKey != nil {
+		var claims jwt.Claims
+
+		claims, err = jwt.NewClientAssertion(c.ClientID, c.TokenURL, clientAssertionLifetime)
+		if err != nil {
+			return
+		}
+
+		var assertion string
+
+		assertion, err = jwt.Sign(c.AssertionAlgorithm, c.AssertionKey, c.AssertionKeyID, claims)
+		if err != nil {
+			return
+		}
+
+		form.Set("client_id", c.ClientID)
+		form.Set("client_assertion_type", clientAssertionType)
+		form.Set("client_assertion", assertion)
+	} else {
+		// Credentials are form-encoded before Basic encoding (RFC 6749, section 2.3.1).
+		builder.BasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+	}
+
+	requested := time.Now()
+
+	res, err := builder.Body(form.Encode()).Send()
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return
+	}
+
+	var response clientCredentialsResponse
+
+	if err = json.Unmarshal(body, &response); err != nil {
+		err = fmt.Errorf("%w: status %s: %w", ErrTokenEndpoint, res.Status, err)
+
+		return
+	}
+
+	if response.Error != "" || response.AccessToken == "" {
+		err = fmt.Errorf("%w: status %s: %s %s", ErrTokenEndpoint, res.Status, response.Error, response.ErrorDescription)
+
+		return
+	}
+
+	token = response.AccessToken
+
+	// The lifetime is counted from the request, as the token may have been issued any time after.
+	if response.ExpiresIn > 0 {
+		expiry = requested.Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+
+	return
+}
+
+// NewClientCredentialsTokenProvider creates a TokenProvider obtaining tokens with the OAuth 2.0
+// client credentials grant, caching them and refreshing them proactively before they expire,
+// or after a 401 Unauthorized response. Set it through ClientConfiguration.TokenProvider.
+//
+// Parameters:
+//   - client: The client sending token requests, DefaultClient if nil. It must not use the
+//     provider itself.
+//   - credentials: The client credentials.
+//
+// Returns:
+//   - provider: The new provider.
+func NewClientCredentialsTokenProvider(client *Client, credentials ClientCredentials) (provider *RefreshingTokenProvider) {
+	if client == nil {
+		client = DefaultClient
+	}
+
+	margin := credentials.RefreshMargin
+
+	if margin <= 0 {
+		margin = DefaultTokenRefreshMargin
+	}
+
+	provider = NewRefreshingTokenProvider(func(ctx context.Context) (token string, expiry time.Time, err error) {
+		return credentials.fetch(ctx, client)
+	}, margin)
+
+	return
+}
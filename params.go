@@ -0,0 +1,96 @@
+package http
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ParamOperation defines how a query parameter is applied to a request URL.
+type ParamOperation int
+
+const (
+	// ParamAdd appends the parameter, keeping any existing values of the same key.
+	ParamAdd ParamOperation = iota
+	// ParamSet replaces every existing value of the key with the parameter. The parameter takes
+	// the position of the first existing value, so the order of the query string is preserved.
+	ParamSet
+)
+
+// Param is a query parameter together with the operation used to apply it.
+type Param struct {
+	Key       string
+	Value     string
+	Operation ParamOperation
+}
+
+// applyParams applies parameters to a raw query string. Existing pairs are kept in their
+// original order and encoding; only the pairs touched by a ParamSet are rewritten.
+//
+// Parameters:
+//   - rawQuery: The raw (encoded) query string, without the leading "?".
+//   - params: The parameters to apply, in order.
+//
+// Returns:
+//   - applied: The resulting raw query string.
+func applyParams(rawQuery string, params []Param) (applied string) {
+	var pairs []string
+
+	if rawQuery != "" {
+		pairs = strings.Split(rawQuery, "&")
+	}
+
+	for _, param := range params {
+		pair := url.QueryEscape(param.Key) + "=" + url.QueryEscape(param.Value)
+
+		if param.Operation != ParamSet {
+			pairs = append(pairs, pair)
+
+			continue
+		}
+
+		replaced := false
+		kept := pairs[:0]
+
+		for _, existing := range pairs {
+			if queryPairKey(existing) != param.Key {
+				kept = append(kept, existing)
+
+				continue
+			}
+
+			if !replaced {
+				kept = append(kept, pair)
+
+				replaced = true
+			}
+		}
+
+		pairs = kept
+
+		if !replaced {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	applied = strings.Join(pairs, "&")
+
+	return
+}
+
+// queryPairKey returns the decoded key of a raw "key=value" query pair. Keys that cannot be
+// decoded are returned as is.
+//
+// Parameters:
+//   - pair: The raw query pair.
+//
+// Returns:
+//   - key: The decoded key.
+func queryPairKey(pair string) (key string) {
+	key, _, _ = strings.Cut(pair, "=")
+
+	if unescaped, err := url.QueryUnescape(key); err == nil {
+		key = unescaped
+	}
+
+	return
+}
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// prewarmConcurrency is the number of hosts Client.Prewarm warms up at once.
+const prewarmConcurrency = 32
+
+// Prewarm warms up hosts ahead of a run (e.g., a time-boxed scan), in parallel, to reduce
+// the latency of the first requests. With DNSPinning, host names are resolved and pinned;
+// without it they are not resolved ahead, as Go keeps no DNS cache to populate. If connect is
+// set, a HEAD request is sent to each host through the client's transport, its response
+// drained, so that the connection established, TLS handshake included, is left idle in the
+// transport's pool for the first requests to reuse.
+//
+// Parameters:
+//   - ctx: The context bounding the warm up.
+//   - hosts: The hosts, as "host", "host:port" or URLs. Hosts without a scheme use https.
+//   - connect: Whether to establish connections, not just resolve the hosts.
+//
+// Returns:
+//   - errs: The errors encountered, keyed by host as given. Empty if every host was warmed up.
+func (c *Client) Prewarm(ctx context.Context, hosts []string, connect bool) (errs map[string]error) {
+	errs = make(map[string]error)
+
+	var mutex sync.Mutex
+
+	var wg sync.WaitGroup
+
+	semaphore := make(chan struct{}, prewarmConcurrency)
+
+	for _, host := range hosts {
+		wg.Add(1)
+
+		semaphore <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-semaphore
+
+				wg.Done()
+			}()
+
+			if err := c.prewarm(ctx, host, connect); err != nil {
+				mutex.Lock()
+
+				errs[host] = err
+
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return
+}
+
+// prewarm warms up a single host.
+//
+// Parameters:
+//   - ctx: The context bounding the warm up.
+//   - host: The host, as "host", "host:port" or a URL.
+//   - connect: Whether to establish a connection, not just resolve the host.
+//
+// Returns:
+//   - err: An error if the host cannot be resolved or connected to. Any response is a success.
+func (c *Client) prewarm(ctx context.Context, host string, connect bool) (err error) {
+	URL := host
+
+	if !strings.Contains(URL, "://") {
+		URL = "https://" + URL
+	}
+
+	target, err := url.Parse(URL)
+	if err != nil {
+		return
+	}
+
+	if err = c.checkDestinationURL(ctx, target); err != nil {
+		return
+	}
+
+	hostname := target.Hostname()
+
+	_, static := c.cfg.StaticHosts[strings.ToLower(hostname)]
+
+	if _, errr := netip.ParseAddr(hostname); errr != nil && !static && c.dnsPinner != nil && !isTrustedDestination(ctx) {
+		if _, err = c.dnsPinner.resolve(ctx, hostname); err != nil {
+			return
+		}
+	}
+
+	if !connect {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, (&url.URL{Scheme: target.Scheme, Host: target.Host, Path: "/"}).String(), http.NoBody)
+	if err != nil {
+		return
+	}
+
+	transport := c.HTTPClient.Transport
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	// The transport is used directly, so that redirects are not followed.
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return
+	}
+
+	// A response read to its end hands its connection back to the pool.
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	err = res.Body.Close()
+
+	return
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrewarm(t *testing.T) {
+	var requests, connections atomic.Int32
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+
+	server.StartTLS()
+
+	defer server.Close()
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	tests := []struct {
+		name            string
+		connect         bool
+		wantConnections int32
+		wantRequests    int32
+		wantReused      bool
+	}{
+		{
+			name: "resolve only",
+		},
+		{
+			name:            "connect",
+			connect:         true,
+			wantConnections: 1,
+			wantRequests:    1,
+			wantReused:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			connections.Store(0)
+
+			client, err := NewClient(&ClientConfiguration{
+				Timeout:    5 * time.Second,
+				DNSPinning: &DNSPinning{},
+				TLS: TLSConfiguration{
+					InsecureSkipVerify: true,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			host := net.JoinHostPort("localhost", port)
+
+			if errs := client.Prewarm(context.Background(), []string{host}, tt.connect); len(errs) > 0 {
+				t.Fatalf("unexpected errors: %v", errs)
+			}
+
+			if _, pinned := client.dnsPinner.pins["localhost"]; !pinned {
+				t.Fatal("localhost was not pinned")
+			}
+
+			// The server accepts connections asynchronously.
+			time.Sleep(50 * time.Millisecond)
+
+			if got := connections.Load(); got != tt.wantConnections {
+				t.Fatalf("got %d connections, want %d", got, tt.wantConnections)
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Fatalf("got %d requests, want %d", got, tt.wantRequests)
+			}
+
+			var reused bool
+
+			trace := &httptrace.ClientTrace{
+				GotConn: func(info httptrace.GotConnInfo) {
+					reused = info.Reused
+				},
+			}
+
+			res, err := client.GET("https://" + host + "/").Context(httptrace.WithClientTrace(context.Background(), trace)).Send()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res.Body.Close()
+
+			if reused != tt.wantReused {
+				t.Fatalf("got a reused connection %t, want %t", reused, tt.wantReused)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.source.hueristiq.com/retrier/backoff"
+)
+
+// ErrUnknownRetryProfile is returned by NewClient when ClientConfiguration.RetryProfile or
+// HostRetryProfiles names a profile missing from RetryProfiles.
+var ErrUnknownRetryProfile = errors.New("unknown retry profile")
+
+// RetryProfile bundles the retry settings of a client under a name, so that retry tuning is
+// picked from a shared library rather than copied around.
+type RetryProfile struct {
+	Retries      int             // Maximum number of retry attempts.
+	RetryWaitMin time.Duration   // Minimum wait time between retries.
+	RetryWaitMax time.Duration   // Maximum wait time between retries.
+	RetryBackoff backoff.Backoff // Backoff strategy, the client's if nil.
+	RetryJitter  Jitter          // Randomization applied to the backoff delays.
+}
+
+const (
+	// RetryProfileAggressive retries quickly and often, for latency-sensitive calls to
+	// services expected to recover within seconds.
+	RetryProfileAggressive = "aggressive"
+	// RetryProfileStandard is a general purpose profile.
+	RetryProfileStandard = "standard"
+	// RetryProfileGentle retries rarely and slowly, for fragile or shared services.
+	RetryProfileGentle = "gentle"
+	// RetryProfileAPIQuotaFriendly waits long between retries, for APIs enforcing quotas.
+	// Retry-After headers still take precedence (see ClientConfiguration.RetryAfterMax).
+	RetryProfileAPIQuotaFriendly = "api-quota-friendly"
+)
+
+// RetryProfiles are the profiles ClientConfiguration.RetryProfile and HostRetryProfiles can
+// name. Entries can be added, or replaced, before clients are created.
+var RetryProfiles = map[string]RetryProfile{
+	RetryProfileAggressive: {
+		Retries:      5,
+		RetryWaitMin: 100 * time.Millisecond,
+		RetryWaitMax: 2 * time.Second,
+		RetryJitter:  JitterFull,
+	},
+	RetryProfileStandard: {
+		Retries:      3,
+		RetryWaitMin: 1 * time.Second,
+		RetryWaitMax: 30 * time.Second,
+		RetryJitter:  JitterEqual,
+	},
+	RetryProfileGentle: {
+		Retries:      2,
+		RetryWaitMin: 5 * time.Second,
+		RetryWaitMax: 1 * time.Minute,
+		RetryJitter:  JitterDecorrelated,
+	},
+	RetryProfileAPIQuotaFriendly: {
+		Retries:      4,
+		RetryWaitMin: 10 * time.Second,
+		RetryWaitMax: 5 * time.Minute,
+		RetryJitter:  JitterFull,
+	},
+}
+
+// retryProfile returns the retry settings of a request: the profile named for its host in
+// ClientConfiguration.HostRetryProfiles, else the one named by RetryProfile, else the
+// settings of the client configuration.
+//
+// Parameters:
+//   - req: The request.
+//
+// Returns:
+//   - profile: The retry settings.
+func (c *Client) retryProfile(req *Request) (profile RetryProfile) {
+	name := c.cfg.RetryProfile
+
+	if hostName, ok := c.cfg.HostRetryProfiles[strings.ToLower(req.URL.Hostname())]; ok {
+		name = hostName
+	}
+
+	profile = c.namedRetryProfile(name)
+
+	return
+}
+
+// namedRetryProfile returns the retry settings of a RetryProfiles entry, else the settings of
+// the client configuration.
+//
+// Parameters:
+//   - name: The name of the profile, empty for none.
+//
+// Returns:
+//   - profile: The retry settings.
+func (c *Client) namedRetryProfile(name string) (profile RetryProfile) {
+	profile = RetryProfile{
+		Retries:      c.cfg.Retries,
+		RetryWaitMin: c.cfg.RetryWaitMin,
+		RetryWaitMax: c.cfg.RetryWaitMax,
+		RetryBackoff: c.RetryBackoff,
+		RetryJitter:  c.cfg.RetryJitter,
+	}
+
+	named, ok := RetryProfiles[name]
+	if !ok {
+		return
+	}
+
+	profile = named
+
+	if profile.RetryBackoff == nil {
+		profile.RetryBackoff = c.RetryBackoff
+	}
+
+	return
+}
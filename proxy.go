@@ -0,0 +1,143 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ErrBadProxyConfig is returned by NewClient when the proxy environment variables (HTTP_PROXY,
+// HTTPS_PROXY, NO_PROXY and their lowercase forms) name a proxy that cannot be used, which
+// would otherwise only surface as an obscure error on every request.
+var ErrBadProxyConfig = errors.New("bad proxy configuration")
+
+// supportedProxySchemes are the proxy URL schemes supported by net/http.
+var supportedProxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// ProxyAuth overrides ClientConfiguration.ProxyAuth for a request when set to a *BasicAuth on
+// its context (see RequestBuilder.ProxyBasicAuth).
+const ProxyAuth ContextOverride = "proxy-auth"
+
+// configureProxy checks the proxy environment of an HTTP client whose transport picks its
+// proxy from it, or stops the pickup if ClientConfiguration.IgnoreProxyEnvironment is set,
+// then makes the proxy function of the transport authenticate to the proxy (see
+// proxyAuthFunc).
+//
+// Parameters:
+//   - httpClient: The HTTP client to configure.
+//
+// Returns:
+//   - err: An error wrapping ErrBadProxyConfig if the environment names an unusable proxy.
+func (c *Client) configureProxy(httpClient *http.Client) (err error) {
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok || transport.Proxy == nil {
+		return
+	}
+
+	// Functions cannot be compared, but their code pointers can.
+	if reflect.ValueOf(transport.Proxy).Pointer() == reflect.ValueOf(http.ProxyFromEnvironment).Pointer() {
+		if c.cfg.IgnoreProxyEnvironment {
+			transport.Proxy = nil
+
+			return
+		}
+
+		if err = checkProxyEnvironment(); err != nil {
+			return
+		}
+	}
+
+	transport.Proxy = proxyAuthFunc(c.cfg.ProxyAuth, transport.Proxy)
+
+	return
+}
+
+// proxyAuthFunc wraps a proxy function to set the credentials of the request (see ProxyAuth),
+// or else of the client, as userinfo of the proxy URLs it returns, replacing any. The
+// transport sends them in the Proxy-Authorization header of CONNECT requests and of proxied
+// HTTP requests, and pools connections per proxy URL, so that tunnels opened with different
+// credentials are not shared.
+//
+// Parameters:
+//   - auth: The credentials of the client, nil if none.
+//   - proxy: The proxy function to wrap.
+//
+// Returns:
+//   - wrapped: The wrapped proxy function.
+func proxyAuthFunc(auth *BasicAuth, proxy func(req *http.Request) (*url.URL, error)) (wrapped func(req *http.Request) (*url.URL, error)) {
+	wrapped = func(req *http.Request) (proxyURL *url.URL, err error) {
+		proxyURL, err = proxy(req)
+		if err != nil || proxyURL == nil {
+			return
+		}
+
+		credentials := auth
+
+		if override, ok := req.Context().Value(ProxyAuth).(*BasicAuth); ok && override != nil {
+			credentials = override
+		}
+
+		if credentials == nil {
+			return
+		}
+
+		authenticated := *proxyURL
+
+		authenticated.User = url.UserPassword(credentials.Username, credentials.Password)
+
+		proxyURL = &authenticated
+
+		return
+	}
+
+	return
+}
+
+// checkProxyEnvironment parses the proxies the environment sets for HTTP and HTTPS requests
+// the way net/http does, which silently ignores the proxies it cannot parse, and checks that
+// they can be used.
+//
+// Parameters: None.
+//
+// Returns:
+//   - err: An error wrapping ErrBadProxyConfig if a proxy is malformed or unsupported.
+func checkProxyEnvironment() (err error) {
+	environment := httpproxy.FromEnvironment()
+
+	for _, variable := range []string{"HTTP_PROXY", "HTTPS_PROXY"} {
+		proxy := environment.HTTPProxy
+
+		if variable == "HTTPS_PROXY" {
+			proxy = environment.HTTPSProxy
+		}
+
+		if proxy == "" {
+			continue
+		}
+
+		proxyURL, parseErr := url.Parse(proxy)
+		if parseErr != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			// Proxies without a scheme (e.g., "proxy:8080") are HTTP proxies.
+			proxyURL, parseErr = url.Parse("http://" + proxy)
+		}
+
+		if parseErr != nil {
+			err = fmt.Errorf("%w: %s: %w", ErrBadProxyConfig, variable, parseErr)
+
+			return
+		}
+
+		if !slices.Contains(supportedProxySchemes, proxyURL.Scheme) || proxyURL.Host == "" {
+			err = fmt.Errorf("%w: %s: unusable proxy %q", ErrBadProxyConfig, variable, proxyURL.Redacted())
+
+			return
+		}
+	}
+
+	return
+}
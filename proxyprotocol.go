@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ErrInvalidProxyProtocol is returned when a PROXY protocol header cannot be built: by
+// NewClient for unsupported versions, and by dials whose source and destination addresses are
+// of different families.
+var ErrInvalidProxyProtocol = errors.New("invalid PROXY protocol configuration")
+
+// proxyProtocolV2Signature starts every PROXY protocol version 2 header.
+var proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// ProxyProtocol configures the HAProxy PROXY protocol header sent at the start of connections
+// to backends expecting one, e.g. to test infrastructure behind a load balancer directly. The
+// announced addresses can be spoofed.
+type ProxyProtocol struct {
+	Version     int            // Version of the protocol: 1 (text) or 2 (binary).
+	Source      netip.AddrPort // Source address announced, the local address of the connection if invalid.
+	Destination netip.AddrPort // Destination address announced, the remote address of the connection if invalid.
+}
+
+// header builds the PROXY protocol header of a connection.
+//
+// Parameters:
+//   - conn: The connection.
+//
+// Returns:
+//   - header: The header.
+//   - err: An error wrapping ErrInvalidProxyProtocol if the addresses are of different families.
+func (p *ProxyProtocol) header(conn net.Conn) (header []byte, err error) {
+	source, destination := p.Source, p.Destination
+
+	if !source.IsValid() {
+		source, err = netip.ParseAddrPort(conn.LocalAddr().String())
+		if err != nil {
+			return
+		}
+	}
+
+	if !destination.IsValid() {
+		destination, err = netip.ParseAddrPort(conn.RemoteAddr().String())
+		if err != nil {
+			return
+		}
+	}
+
+	source = netip.AddrPortFrom(source.Addr().Unmap(), source.Port())
+	destination = netip.AddrPortFrom(destination.Addr().Unmap(), destination.Port())
+
+	if source.Addr().Is4() != destination.Addr().Is4() {
+		err = fmt.Errorf("%w: source %s and destination %s are of different families", ErrInvalidProxyProtocol, source, destination)
+
+		return
+	}
+
+	if p.Version == 1 {
+		family := "TCP6"
+
+		if source.Addr().Is4() {
+			family = "TCP4"
+		}
+
+		header = fmt.Appendf(nil, "PROXY %s %s %s %d %d\r\n", family, source.Addr(), destination.Addr(), source.Port(), destination.Port())
+
+		return
+	}
+
+	// Version 2, PROXY command, then TCP over IPv4 (0x11) or IPv6 (0x21).
+	family := byte(0x21)
+
+	if source.Addr().Is4() {
+		family = 0x11
+	}
+
+	sourceIP, destinationIP := source.Addr().AsSlice(), destination.Addr().AsSlice()
+
+	header = append(header, proxyProtocolV2Signature...)
+	header = append(header, 0x21, family)
+	header = binary.BigEndian.AppendUint16(header, uint16(2*len(sourceIP)+4)) //nolint:gosec // At most 36.
+	header = append(header, sourceIP...)
+	header = append(header, destinationIP...)
+	header = binary.BigEndian.AppendUint16(header, source.Port())
+	header = binary.BigEndian.AppendUint16(header, destination.Port())
+
+	return
+}
+
+// proxyProtocolDialContext wraps a dial function to send the PROXY protocol header configured
+// for the dialed host through ClientConfiguration.ProxyProtocol, before any other data (TLS
+// handshake included).
+//
+// Parameters:
+//   - hosts: The PROXY protocol configurations, keyed by lowercased hostname.
+//   - dial: The dial function to wrap.
+//
+// Returns:
+//   - wrapped: The wrapped dial function.
+func proxyProtocolDialContext(hosts map[string]*ProxyProtocol, dial func(ctx context.Context, network, address string) (net.Conn, error)) (wrapped func(ctx context.Context, network, address string) (net.Conn, error)) {
+	wrapped = func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		conn, err = dial(ctx, network, address)
+		if err != nil {
+			return
+		}
+
+		host, _, _ := net.SplitHostPort(address)
+
+		protocol, ok := hosts[strings.ToLower(host)]
+		if !ok {
+			return
+		}
+
+		header, err := protocol.header(conn)
+		if err == nil {
+			_, err = conn.Write(header)
+		}
+
+		if err != nil {
+			conn.Close()
+
+			conn = nil
+		}
+
+		return
+	}
+
+	return
+}
+
+// checkProxyProtocols checks the versions of the PROXY protocol configurations.
+//
+// Parameters:
+//   - hosts: The PROXY protocol configurations.
+//
+// Returns:
+//   - err: An error wrapping ErrInvalidProxyProtocol for the first invalid configuration.
+func checkProxyProtocols(hosts map[string]*ProxyProtocol) (err error) {
+	for host, protocol := range hosts {
+		if protocol == nil || (protocol.Version != 1 && protocol.Version != 2) {
+			err = fmt.Errorf("%w: %s: unsupported version", ErrInvalidProxyProtocol, host)
+
+			return
+		}
+	}
+
+	return
+}
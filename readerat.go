@@ -0,0 +1,320 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+const (
+	// DefaultRemoteBlockSize is the size of the blocks a RemoteReaderAt fetches when no block
+	// size is given.
+	DefaultRemoteBlockSize = 64 << 10
+	// DefaultRemoteCachedBlocks is the number of blocks a RemoteReaderAt caches when no cache
+	// size is given.
+	DefaultRemoteCachedBlocks = 64
+)
+
+var (
+	// ErrRangeNotSupported is returned when a server does not answer range requests with partial
+	// content.
+	ErrRangeNotSupported = errors.New("server does not support range requests")
+	// ErrResourceChanged is returned when a remote resource changed between range requests, so
+	// that its blocks would mix two versions.
+	ErrResourceChanged = errors.New("remote resource changed")
+)
+
+// RemoteReaderAt exposes a remote resource as an io.ReaderAt, fetching it block by block with
+// range requests and caching the most recently used blocks, so that, e.g., archive/zip can
+// read a large remote archive without downloading it. Range requests after the first one are
+// pinned to the version it returned with If-Range, using its strong ETag or Last-Modified date.
+// It is safe for concurrent use.
+type RemoteReaderAt struct {
+	ctx         context.Context //nolint:containedctx // The context range requests are bound to.
+	client      *Client
+	URL         string
+	size        int64
+	validator   string
+	blockSize   int64
+	cacheBlocks int
+
+	mutex  sync.Mutex
+	blocks map[int64][]byte
+	recent []int64
+}
+
+// Size returns the size of the remote resource.
+//
+// Parameters: None.
+//
+// Returns:
+//   - size: The size, in bytes.
+func (r *RemoteReaderAt) Size() (size int64) {
+	size = r.size
+
+	return
+}
+
+// ReadAt reads len(p) bytes of the remote resource starting at offset off.
+//
+// Parameters:
+//   - p: The buffer to read into.
+//   - off: The offset to read from.
+//
+// Returns:
+//   - n: The number of bytes read.
+//   - err: io.EOF if the end of the resource was reached, or the error of a range request.
+func (r *RemoteReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		err = fmt.Errorf("negative offset %d", off)
+
+		return
+	}
+
+	for n < len(p) {
+		position := off + int64(n)
+
+		if position >= r.size {
+			err = io.EOF
+
+			return
+		}
+
+		index := position / r.blockSize
+
+		var block []byte
+
+		block, err = r.block(index)
+		if err != nil {
+			return
+		}
+
+		start := position - index*r.blockSize
+
+		if start >= int64(len(block)) {
+			err = io.ErrUnexpectedEOF
+
+			return
+		}
+
+		n += copy(p[n:], block[start:])
+	}
+
+	return
+}
+
+// block returns a block of the resource, from the cache or fetched with a range request.
+//
+// Parameters:
+//   - index: The index of the block.
+//
+// Returns:
+//   - block: The content of the block.
+//   - err: An error if the block cannot be fetched.
+func (r *RemoteReaderAt) block(index int64) (block []byte, err error) {
+	r.mutex.Lock()
+
+	block, ok := r.blocks[index]
+	if ok {
+		r.touch(index)
+	}
+
+	r.mutex.Unlock()
+
+	if ok {
+		return
+	}
+
+	first := index * r.blockSize
+	last := min(first+r.blockSize, r.size) - 1
+
+	block, total, validator, err := r.fetch(first, last)
+	if err != nil {
+		return
+	}
+
+	if total != r.size || (r.validator != "" && validator != "" && validator != r.validator) {
+		block = nil
+		err = fmt.Errorf("%w: %s", ErrResourceChanged, r.URL)
+
+		return
+	}
+
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.blocks[index] = block
+
+	r.touch(index)
+
+	for len(r.recent) > r.cacheBlocks {
+		delete(r.blocks, r.recent[0])
+
+		r.recent = r.recent[1:]
+	}
+
+	return
+}
+
+// touch marks a block as the most recently used one. The mutex must be held.
+//
+// Parameters:
+//   - index: The index of the block.
+//
+// Returns: None.
+func (r *RemoteReaderAt) touch(index int64) {
+	r.recent = slices.DeleteFunc(r.recent, func(recent int64) bool { return recent == index })
+	r.recent = append(r.recent, index)
+}
+
+// fetch sends a range request for the bytes first to last, inclusive, conditional on the
+// validator of the resource once it is known.
+//
+// Parameters:
+//   - first: The offset of the first byte.
+//   - last: The offset of the last byte.
+//
+// Returns:
+//   - content: The bytes received.
+//   - total: The size of the resource, as reported by the Content-Range header.
+//   - validator: The validator of the version received, empty if the server sent none.
+//   - err: ErrResourceChanged if the server answered a conditional request with the full
+//     resource, ErrRangeNotSupported if it answered with another range or the full resource,
+//     or the error of the request.
+func (r *RemoteReaderAt) fetch(first, last int64) (content []byte, total int64, validator string, err error) {
+	builder := r.client.GET(r.URL).
+		Context(r.ctx).
+		SetHeader(headers.Range.String(), fmt.Sprintf("bytes=%d-%d", first, last))
+
+	if r.validator != "" {
+		builder.SetHeader(headers.IfRange.String(), r.validator)
+	}
+
+	res, err := builder.Send()
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	validator = rangeValidator(res.Header)
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusRequestedRangeNotSatisfiable:
+		// An empty resource has no byte to satisfy any range with, and a shrunk one reports its
+		// new size, so the size is all there is to learn.
+		if _, err = fmt.Sscanf(res.Header.Get(headers.ContentRange.String()), "bytes */%d", &total); err != nil {
+			err = fmt.Errorf("%w: status %s", ErrRangeNotSupported, res.Status)
+		}
+
+		return
+	case http.StatusOK:
+		if r.validator != "" {
+			err = fmt.Errorf("%w: status %s", ErrResourceChanged, res.Status)
+
+			return
+		}
+
+		// Some servers, e.g. net/http, answer ranges of an empty resource in full.
+		if res.ContentLength == 0 {
+			return
+		}
+
+		fallthrough
+	default:
+		err = fmt.Errorf("%w: status %s", ErrRangeNotSupported, res.Status)
+
+		return
+	}
+
+	var from, to int64
+
+	if _, err = fmt.Sscanf(res.Header.Get(headers.ContentRange.String()), "bytes %d-%d/%d", &from, &to, &total); err != nil || from != first || to > last {
+		err = fmt.Errorf("%w: unexpected Content-Range %q", ErrRangeNotSupported, res.Header.Get(headers.ContentRange.String()))
+
+		return
+	}
+
+	content, err = io.ReadAll(io.LimitReader(res.Body, to-from+1))
+
+	return
+}
+
+// rangeValidator returns the validator to pin range requests to with If-Range: the ETag of a
+// response if it is strong, as If-Range does not accept weak ones, or its Last-Modified date.
+//
+// Parameters:
+//   - header: The header of the response.
+//
+// Returns:
+//   - validator: The validator, empty if the response has none.
+func rangeValidator(header http.Header) (validator string) {
+	validator = header.Get(headers.ETag.String())
+
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = header.Get(headers.LastModified.String())
+	}
+
+	return
+}
+
+// NewRemoteReaderAt creates a RemoteReaderAt for a remote resource, sending a first range
+// request to learn its size and check that the server supports range requests.
+//
+// Parameters:
+//   - ctx: The context range requests are bound to.
+//   - client: The client sending the range requests.
+//   - URL: The URL of the resource.
+//   - blockSize: The size of the blocks fetched, DefaultRemoteBlockSize if not positive.
+//   - cacheBlocks: The number of blocks cached, DefaultRemoteCachedBlocks if not positive.
+//
+// Returns:
+//   - reader: The new reader.
+//   - err: ErrRangeNotSupported if the server does not support range requests, or the error
+//     of the first request.
+func NewRemoteReaderAt(ctx context.Context, client *Client, URL string, blockSize int64, cacheBlocks int) (reader *RemoteReaderAt, err error) {
+	if blockSize <= 0 {
+		blockSize = DefaultRemoteBlockSize
+	}
+
+	if cacheBlocks <= 0 {
+		cacheBlocks = DefaultRemoteCachedBlocks
+	}
+
+	reader = &RemoteReaderAt{
+		ctx:         ctx,
+		client:      client,
+		URL:         URL,
+		blockSize:   blockSize,
+		cacheBlocks: cacheBlocks,
+		blocks:      make(map[int64][]byte),
+	}
+
+	// The first block is fetched to learn the size and the validator, and cached.
+	first, size, validator, err := reader.fetch(0, blockSize-1)
+	if err == nil && size > 0 && len(first) == 0 {
+		err = fmt.Errorf("%w: no content for a resource of %d bytes", ErrRangeNotSupported, size)
+	}
+
+	if err != nil {
+		reader = nil
+
+		return
+	}
+
+	reader.size = size
+	reader.validator = validator
+	reader.blocks[0] = first
+	reader.recent = append(reader.recent, 0)
+
+	return
+}
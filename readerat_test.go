@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRemoteReaderAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		changed  string
+		etag     bool
+		empty416 bool
+		wantSize int64
+		wantRead string
+		wantErr  error
+	}{
+		{
+			name:     "unchanged resource",
+			content:  "0123456789",
+			changed:  "0123456789",
+			etag:     true,
+			wantSize: 10,
+			wantRead: "89",
+		},
+		{
+			name:     "empty resource answered with 416",
+			empty416: true,
+			wantErr:  io.EOF,
+		},
+		{
+			name:    "empty resource answered in full",
+			wantErr: io.EOF,
+		},
+		{
+			name:     "resource changed with the same size",
+			content:  "0123456789",
+			changed:  "abcdefghij",
+			etag:     true,
+			wantSize: 10,
+			wantErr:  ErrResourceChanged,
+		},
+		{
+			name:     "resource changed size without validator",
+			content:  "0123456789",
+			changed:  "0123456789abc",
+			wantSize: 10,
+			wantErr:  ErrResourceChanged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mutex sync.Mutex
+
+			content := tt.content
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+
+				current := content
+
+				mutex.Unlock()
+
+				if current == "" && tt.empty416 {
+					w.Header().Set("Content-Range", "bytes */0")
+					w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+
+					return
+				}
+
+				if tt.etag {
+					w.Header().Set("ETag", `"`+current+`"`)
+				}
+
+				http.ServeContent(w, r, "", time.Time{}, strings.NewReader(current))
+			}))
+
+			defer server.Close()
+
+			client, err := NewClient(&ClientConfiguration{Timeout: 5 * time.Second})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := NewRemoteReaderAt(context.Background(), client, server.URL, 4, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if reader.Size() != tt.wantSize {
+				t.Fatalf("got size %d, want %d", reader.Size(), tt.wantSize)
+			}
+
+			mutex.Lock()
+
+			content = tt.changed
+
+			mutex.Unlock()
+
+			p := make([]byte, 2)
+
+			n, err := reader.ReadAt(p, 8)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if string(p[:n]) != tt.wantRead {
+				t.Fatalf("got %q, want %q", p[:n], tt.wantRead)
+			}
+		})
+	}
+}
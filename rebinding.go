@@ -0,0 +1,183 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDNSPinTTL is how long the addresses of a host stay pinned when DNSPinning.TTL is zero.
+const DefaultDNSPinTTL = 5 * time.Minute
+
+// ErrDNSRebinding is returned when a host pinned to public addresses resolves to internal
+// ones, as attacker-controlled DNS servers do to turn repeated fetches of a URL against
+// internal services.
+var ErrDNSRebinding = errors.New("DNS rebinding detected")
+
+// DNSPinning pins the addresses host names resolve to for the lifetime of a client: hosts are
+// dialed at the addresses they first resolved to until the pin expires, and a host that
+// resolved to public addresses is refused with ErrDNSRebinding if it later resolves to
+// internal ones. IP addresses, including StaticHosts ones, are not pinned. Requests carrying
+// the TrustedDestination override are exempted.
+type DNSPinning struct {
+	TTL      time.Duration  // How long resolved addresses are pinned before the host is resolved again, DefaultDNSPinTTL if zero.
+	Internal []netip.Prefix // Ranges public hosts must not move to, DefaultDeniedRanges if nil.
+}
+
+// dnsPin holds the addresses a host is pinned to.
+type dnsPin struct {
+	addrs    []netip.Addr
+	expiry   time.Time
+	internal bool
+}
+
+// dnsPinner resolves and pins host names for DNSPinning.
+type dnsPinner struct {
+	ttl      time.Duration
+	internal []netip.Prefix
+
+	mutex sync.Mutex
+	pins  map[string]dnsPin
+}
+
+// isInternal reports whether an address is part of the internal ranges.
+//
+// Parameters:
+//   - addr: The address to check.
+//
+// Returns:
+//   - internal: Whether the address is internal.
+func (p *dnsPinner) isInternal(addr netip.Addr) (internal bool) {
+	internal = slices.ContainsFunc(p.internal, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+
+	return
+}
+
+// resolve returns the addresses a host is pinned to, resolving and pinning it if it is not
+// pinned or its pin expired.
+//
+// Parameters:
+//   - ctx: The context of the dial.
+//   - host: The host name.
+//
+// Returns:
+//   - addrs: The pinned addresses.
+//   - err: An error wrapping ErrDNSRebinding if a public host resolves to internal addresses,
+//     or the error of the resolver.
+func (p *dnsPinner) resolve(ctx context.Context, host string) (addrs []netip.Addr, err error) {
+	host = strings.ToLower(host)
+
+	p.mutex.Lock()
+
+	pin, pinned := p.pins[host]
+
+	p.mutex.Unlock()
+
+	if pinned && time.Now().Before(pin.expiry) {
+		addrs = pin.addrs
+
+		return
+	}
+
+	addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return
+	}
+
+	for i := range addrs {
+		addrs[i] = addrs[i].Unmap()
+	}
+
+	internal := slices.ContainsFunc(addrs, p.isInternal)
+
+	// The previous pin is kept, so that the host is refused until it resolves back to public addresses.
+	if pinned && !pin.internal && internal {
+		err = fmt.Errorf("%w: %s moved from %v to %v", ErrDNSRebinding, host, pin.addrs, addrs)
+		addrs = nil
+
+		return
+	}
+
+	p.mutex.Lock()
+
+	p.pins[host] = dnsPin{
+		addrs:    addrs,
+		expiry:   time.Now().Add(p.ttl),
+		internal: internal,
+	}
+
+	p.mutex.Unlock()
+
+	return
+}
+
+// dialContext wraps a dial function so that it dials host names at their pinned addresses.
+//
+// Parameters:
+//   - dial: The dial function to wrap.
+//
+// Returns:
+//   - wrapped: The wrapping dial function.
+func (p *dnsPinner) dialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) (wrapped func(ctx context.Context, network, address string) (net.Conn, error)) {
+	wrapped = func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return
+		}
+
+		if _, errr := netip.ParseAddr(host); errr == nil || isTrustedDestination(ctx) {
+			conn, err = dial(ctx, network, address)
+
+			return
+		}
+
+		addrs, err := p.resolve(ctx, host)
+		if err != nil {
+			return
+		}
+
+		for _, addr := range addrs {
+			conn, err = dial(ctx, network, net.JoinHostPort(addr.String(), port))
+			if err == nil {
+				return
+			}
+		}
+
+		return
+	}
+
+	return
+}
+
+// newDNSPinner creates a dnsPinner without pins.
+//
+// Parameters:
+//   - pinning: The pinning configuration.
+//
+// Returns:
+//   - pinner: The new pinner.
+func newDNSPinner(pinning *DNSPinning) (pinner *dnsPinner) {
+	pinner = &dnsPinner{
+		ttl:      pinning.TTL,
+		internal: pinning.Internal,
+		pins:     make(map[string]dnsPin),
+	}
+
+	if pinner.ttl <= 0 {
+		pinner.ttl = DefaultDNSPinTTL
+	}
+
+	if pinner.internal == nil {
+		pinner.internal = DefaultDeniedRanges
+	}
+
+	return
+}
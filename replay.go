@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+)
+
+// maxTransparentRetries is the number of times an attempt is transparently sent again after
+// an HTTP/2 connection or stream error.
+const maxTransparentRetries = 2
+
+// idempotentMethods are the methods whose requests may be sent again after a failure that
+// does not guarantee the server left them unprocessed.
+var idempotentMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodTrace,
+	http.MethodPut,
+	http.MethodDelete,
+}
+
+var (
+	// goAwayErrorRegex is a regular expression to match the error returned by net/http and
+	// golang.org/x/net/http2 when the server closes the connection with a GOAWAY frame. These
+	// errors aren't typed consistently so we resort to matching on the error string.
+	goAwayErrorRegex = regexp.MustCompile(`http2: server sent GOAWAY and closed the connection; LastStreamID=\d+, ErrCode=(\w+)`)
+
+	// streamErrorRegex is a regular expression to match the error returned by net/http and
+	// golang.org/x/net/http2 when the server resets a stream.
+	streamErrorRegex = regexp.MustCompile(`stream error: stream ID \d+; (\w+)`)
+)
+
+// isTransparentlyRetryable reports whether an attempt that failed with an HTTP/2 connection or
+// stream error can be sent again on a new stream without counting as a retry: always if the
+// server refused the stream, which guarantees it did not process the request, and for
+// idempotent requests otherwise.
+//
+// Parameters:
+//   - req: The request of the attempt.
+//   - err: The error the attempt failed with.
+//
+// Returns:
+//   - retryable: Whether the attempt can be sent again.
+func isTransparentlyRetryable(req *Request, err error) (retryable bool) {
+	if err == nil {
+		return
+	}
+
+	var code string
+
+	if match := goAwayErrorRegex.FindStringSubmatch(err.Error()); match != nil {
+		code = match[1]
+	} else if match := streamErrorRegex.FindStringSubmatch(err.Error()); match != nil {
+		code = match[1]
+	} else {
+		return
+	}
+
+	retryable = code == "REFUSED_STREAM" || slices.Contains(idempotentMethods, req.Method)
+
+	return
+}
+
+// TransparentRetries returns the number of attempts transparently sent again after an HTTP/2
+// GOAWAY or stream error. These do not count against ClientConfiguration.Retries.
+//
+// Parameters: None.
+//
+// Returns:
+//   - count: The number of transparent retries.
+func (c *Client) TransparentRetries() (count uint64) {
+	count = c.transparentRetries.Load()
+
+	return
+}
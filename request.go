@@ -4,8 +4,14 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"maps"
 	"net/http"
 	"net/http/httputil"
+	"slices"
+	"strings"
+
+	hqgoreaderutil "github.com/hueristiq/hqgoutils/reader"
+	"go.source.hueristiq.com/http/headers"
 )
 
 // Request wraps the standard http.Request struct and adds fields for tracking
@@ -19,6 +25,12 @@ type Request struct {
 	*http.Request
 
 	Metrics Metrics // Tracks various metrics related to request handling
+
+	requestBodyTransformers  []BodyTransformer
+	responseBodyTransformers []BodyTransformer
+	onRetry                  RetryHandler
+	decompress               bool   // Whether the client advertises gzip on attempts and decodes responses itself (see claimDecompression).
+	acceptEncoding           string // Accept-Encoding set on attempts for the host of the request (see applyAcceptEncoding).
 }
 
 // WithContext creates a new Request with the provided context. This allows you
@@ -61,9 +73,10 @@ func (r *Request) BodyBytes() (body []byte, err error) {
 	return
 }
 
-// Clone creates a deep copy of the Request, resetting its Metrics and duplicating
-// the Auth data, if available. This is useful for generating a new request instance
-// while retaining most properties.
+// Clone creates a deep copy of the Request, resetting its Metrics. Headers, URL and
+// a reusable body are duplicated, so the clone can be modified and sent concurrently
+// with the original without sharing mutable state. Bodies that are not reusable are
+// shared, as they cannot be read without consuming them.
 //
 // Parameters:
 //   - ctx: The context to associate with the cloned request.
@@ -72,10 +85,30 @@ func (r *Request) BodyBytes() (body []byte, err error) {
 //   - req: A new Request with the same data but reset Metrics and context.
 func (r *Request) Clone(ctx context.Context) (req *Request) {
 	req = &Request{
-		Request: r.Request.Clone(ctx),
-		Metrics: Metrics{},
+		Request:                  r.Request.Clone(ctx),
+		Metrics:                  Metrics{},
+		requestBodyTransformers:  r.requestBodyTransformers,
+		responseBodyTransformers: r.responseBodyTransformers,
+		onRetry:                  r.onRetry,
+	}
+
+	body, ok := r.Request.Body.(*hqgoreaderutil.ReusableReadCloser)
+	if !ok {
+		return
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return
+	}
+
+	clone, err := hqgoreaderutil.NewReusableReadCloser(content)
+	if err != nil {
+		return
 	}
 
+	req.Request.Body = clone
+
 	return
 }
 
@@ -115,12 +148,114 @@ func (r *Request) Dump() (dump []byte, err error) {
 	return
 }
 
+// ToCurl renders the Request as a curl command, e.g. to reproduce it from a shell. The body
+// is included if it is reusable, or can be obtained again through GetBody.
+//
+// Parameters:
+//   - redact: Whether to redact the values of SensitiveHeaders, credential-like query
+//     parameters and the URL password.
+//
+// Returns:
+//   - command: The curl command, with every argument single-quoted.
+//   - err: An error if the body cannot be read.
+func (r *Request) ToCurl(redact bool) (command string, err error) {
+	URL, header := r.URL.String(), r.Header
+
+	if redact {
+		URL, header = redactedURL(r.URL), redactHeader(header)
+	}
+
+	args := []string{"curl"}
+
+	if r.Method != "" && r.Method != http.MethodGet {
+		args = append(args, "-X", shellQuote(r.Method))
+	}
+
+	args = append(args, shellQuote(URL))
+
+	if r.Host != "" && r.Host != r.URL.Host {
+		args = append(args, "-H", shellQuote(headers.Host.String()+": "+r.Host))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[key] {
+			args = append(args, "-H", shellQuote(key+": "+value))
+		}
+	}
+
+	body, err := r.reusableBody()
+	if err != nil {
+		return
+	}
+
+	if len(body) > 0 {
+		args = append(args, "--data-binary", shellQuote(string(body)))
+	}
+
+	command = strings.Join(args, " ")
+
+	return
+}
+
+// reusableBody reads the body of the Request without consuming it.
+//
+// Parameters: None.
+//
+// Returns:
+//   - body: The body, nil if there is none or it is not reusable.
+//   - err: An error if the body cannot be read.
+func (r *Request) reusableBody() (body []byte, err error) {
+	if reusable, ok := r.Body.(*hqgoreaderutil.ReusableReadCloser); ok {
+		body, err = io.ReadAll(reusable)
+
+		return
+	}
+
+	if r.GetBody == nil {
+		return
+	}
+
+	copied, err := r.GetBody()
+	if err != nil {
+		return
+	}
+
+	defer copied.Close()
+
+	body, err = io.ReadAll(copied)
+
+	return
+}
+
+// shellQuote quotes a string as a single POSIX shell word.
+//
+// Parameters:
+//   - s: The string.
+//
+// Returns:
+//   - quoted: The string in single quotes, with embedded single quotes escaped.
+func shellQuote(s string) (quoted string) {
+	quoted = "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+
+	return
+}
+
 // Metrics represents statistics related to request handling. These metrics are
 // useful for tracking performance or issues encountered during the request lifecycle.
 type Metrics struct {
 	Failures    int // Failures is the number of failed requests
 	Retries     int // Retries is the number of retries for the request
 	DrainErrors int // DrainErrors is number of errors occurred in draining response body
+	Hedges      int // Hedges is the number of duplicate attempts sent (see ClientConfiguration.HedgeDelay)
+
+	RequestID string // RequestID is the ID of the request (see ClientConfiguration.RequestIDHeader), also logged with it
+
+	Tarpit bool // Tarpit is whether an attempt was aborted as too slow or unbounded (see ErrSlowResponse and ErrTarpit)
+
+	Protocol        string                       // Protocol is the protocol that served the final response (e.g., "HTTP/2.0")
+	ContentEncoding string                       // ContentEncoding is the content coding of the final response (e.g., "gzip", "identity")
+	Timings         []Timing                     // Timings holds the timing breakdown of every attempt
+	ServerTiming    []headers.ServerTimingMetric // ServerTiming holds the backend timings reported by the final response
 }
 
 // NewRequest creates a new Request without context using the specified HTTP method, URL, and body.
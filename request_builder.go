@@ -1,17 +1,62 @@
 package http
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"maps"
 	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	hqgoreaderutil "github.com/hueristiq/hqgoutils/reader"
+	"go.source.hueristiq.com/http/headers"
+	"go.source.hueristiq.com/http/methods"
+	"golang.org/x/text/language"
 )
 
 type RequestBuilder struct {
 	client *Client
-	method string
+	method methods.Method
 	_URL   string
 	header http.Header
+	params []Param
 	body   interface{}
+	err    error
+
+	defaultParams   map[string]string
+	onInformational InformationalResponseHandler
+	ctx             context.Context //nolint:containedctx // Context the built request is bound to.
+	timeout         time.Duration
+	retryJitter     *Jitter
+	proxyAuth       *BasicAuth
+	certificate     *tls.Certificate
+	tlsConfig       *tls.Config
+	insecure        bool
+
+	requestBodyTransformers  []BodyTransformer
+	responseBodyTransformers []BodyTransformer
+	onRetry                  RetryHandler
+
+	mutex sync.Mutex // Guards body and err while Clone buffers a body.
 }
 
+// InformationalResponseHandler defines a function type that observes informational (1xx)
+// responses, such as 103 Early Hints, received before the final response.
+//
+// Parameters:
+//   - code: The informational status code.
+//   - header: The header fields of the informational response.
+//
+// Returns:
+//   - err: A non-nil error aborts the request with that error.
+type InformationalResponseHandler func(code int, header textproto.MIMEHeader) (err error)
+
 func (r *RequestBuilder) AddHeader(key, value string) *RequestBuilder {
 	r.header.Add(key, value)
 
@@ -24,19 +69,350 @@ func (r *RequestBuilder) SetHeader(key, value string) *RequestBuilder {
 	return r
 }
 
+// BasicAuth sets the Authorization header of the request using the Basic scheme, overriding ClientConfiguration.BasicAuth.
+func (r *RequestBuilder) BasicAuth(username, password string) *RequestBuilder {
+	r.header.Set(headers.Authorization.String(), headers.BuildBasicAuth(username, password))
+
+	return r
+}
+
+// ProxyBasicAuth sets the credentials sent to the proxy with the Basic scheme, overriding ClientConfiguration.ProxyAuth.
+func (r *RequestBuilder) ProxyBasicAuth(username, password string) *RequestBuilder {
+	r.proxyAuth = &BasicAuth{Username: username, Password: password}
+
+	return r
+}
+
+// ClientCertificate sets the client certificate presented by the request, overriding the
+// client's, through a transport of its own (see ClientCertificate).
+func (r *RequestBuilder) ClientCertificate(certificate tls.Certificate) *RequestBuilder {
+	r.certificate = &certificate
+
+	return r
+}
+
+// TLSConfig sets the TLS client configuration of the request, replacing the transport's,
+// through a transport of its own (see TLSConfig).
+func (r *RequestBuilder) TLSConfig(config *tls.Config) *RequestBuilder {
+	r.tlsConfig = config
+
+	return r
+}
+
+// InsecureSkipVerify disables the verification of server certificates for the request,
+// through a transport of its own (see InsecureSkipVerify).
+func (r *RequestBuilder) InsecureSkipVerify() *RequestBuilder {
+	r.insecure = true
+
+	return r
+}
+
+// DelHeader removes a header from the request, including a default header inherited from the client.
+func (r *RequestBuilder) DelHeader(key string) *RequestBuilder {
+	r.header.Del(key)
+
+	return r
+}
+
+// SetHeaderIfAbsent sets a header only if the request does not carry it yet, either
+// from an earlier call or as a default header inherited from the client.
+func (r *RequestBuilder) SetHeaderIfAbsent(key, value string) *RequestBuilder {
+	if _, ok := r.header[http.CanonicalHeaderKey(key)]; !ok {
+		r.header.Set(key, value)
+	}
+
+	return r
+}
+
+// OmitHeaders removes the given headers from the request, including default headers
+// inherited from the client.
+func (r *RequestBuilder) OmitHeaders(keys ...string) *RequestBuilder {
+	for _, key := range keys {
+		r.header.Del(key)
+	}
+
+	return r
+}
+
+// OmitParams prevents the given default query parameters of the client from being
+// added to the request. Parameters already present in the URL are not affected.
+func (r *RequestBuilder) OmitParams(keys ...string) *RequestBuilder {
+	for _, key := range keys {
+		delete(r.defaultParams, key)
+	}
+
+	return r
+}
+
+// ReplaceParams prevents all default query parameters of the client from being added
+// to the request, so only the URL's own and the request's parameters are sent.
+func (r *RequestBuilder) ReplaceParams() *RequestBuilder {
+	r.defaultParams = nil
+
+	return r
+}
+
+// AddParam appends a query parameter to the request URL, keeping any existing
+// values of the same key, including those already present in the URL.
+func (r *RequestBuilder) AddParam(key, value string) *RequestBuilder {
+	r.params = append(r.params, Param{Key: key, Value: value, Operation: ParamAdd})
+
+	return r
+}
+
+// SetParam sets a query parameter of the request URL, replacing any existing
+// values of the same key, including those already present in the URL.
+func (r *RequestBuilder) SetParam(key, value string) *RequestBuilder {
+	r.params = append(r.params, Param{Key: key, Value: value, Operation: ParamSet})
+
+	return r
+}
+
+// Params applies the given query parameters, each with its own operation, in order.
+func (r *RequestBuilder) Params(params ...Param) *RequestBuilder {
+	r.params = append(r.params, params...)
+
+	return r
+}
+
+// AcceptLanguage sets the Accept-Language header from the given language tags,
+// listed in order of preference.
+func (r *RequestBuilder) AcceptLanguage(tags ...language.Tag) *RequestBuilder {
+	r.header.Set(headers.AcceptLanguage.String(), headers.BuildAcceptLanguage(tags...))
+
+	return r
+}
+
+// OnInformational registers a handler invoked for every informational (1xx) response
+// received while the request is in flight, e.g. to inspect 103 Early Hints Link headers.
+func (r *RequestBuilder) OnInformational(handler InformationalResponseHandler) *RequestBuilder {
+	r.onInformational = handler
+
+	return r
+}
+
+// Context binds the built request to ctx (e.g., a Budget context) instead of context.Background.
+func (r *RequestBuilder) Context(ctx context.Context) *RequestBuilder {
+	r.ctx = ctx
+
+	return r
+}
+
+// TransformRequestBody appends stages transforming the request body, run after the client's.
+func (r *RequestBuilder) TransformRequestBody(transformers ...BodyTransformer) *RequestBuilder {
+	r.requestBodyTransformers = append(r.requestBodyTransformers, transformers...)
+
+	return r
+}
+
+// TransformResponseBody appends stages transforming the response body, run after the client's.
+func (r *RequestBuilder) TransformResponseBody(transformers ...BodyTransformer) *RequestBuilder {
+	r.responseBodyTransformers = append(r.responseBodyTransformers, transformers...)
+
+	return r
+}
+
+// Timeout overrides the client's Timeout for the request, both for each attempt and for the
+// request as a whole, retries included.
+func (r *RequestBuilder) Timeout(timeout time.Duration) *RequestBuilder {
+	r.timeout = timeout
+
+	return r
+}
+
+// RetryJitter overrides the client's RetryJitter for the request.
+func (r *RequestBuilder) RetryJitter(jitter Jitter) *RequestBuilder {
+	r.retryJitter = &jitter
+
+	return r
+}
+
+// OnRetry registers a handler called before every retry of the request, after the client's.
+func (r *RequestBuilder) OnRetry(handler RetryHandler) *RequestBuilder {
+	r.onRetry = handler
+
+	return r
+}
+
+// Method overrides the HTTP method of the request.
+func (r *RequestBuilder) Method(method methods.Method) *RequestBuilder {
+	r.method = method
+
+	return r
+}
+
 func (r *RequestBuilder) Body(body interface{}) *RequestBuilder {
 	r.body = body
 
 	return r
 }
 
+// Clone returns a deep copy of the builder. Headers, parameters and the body are copied, so
+// the clone can be modified and sent independently, e.g. to derive variations of a template
+// request from several goroutines. A body given as a plain io.Reader is buffered first, once,
+// on the original, so that both it and its clones can be built and sent.
+func (r *RequestBuilder) Clone() (clone *RequestBuilder) {
+	r.mutex.Lock()
+
+	if reader, ok := r.body.(io.Reader); ok {
+		if _, reusable := reader.(*hqgoreaderutil.ReusableReadCloser); !reusable {
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				r.err = err
+			}
+
+			r.body = content
+		}
+	}
+
+	body, err := copyBody(r.body), r.err
+
+	r.mutex.Unlock()
+
+	clone = &RequestBuilder{
+		client:          r.client,
+		method:          r.method,
+		_URL:            r._URL,
+		header:          r.header.Clone(),
+		params:          slices.Clone(r.params),
+		defaultParams:   maps.Clone(r.defaultParams),
+		body:            body,
+		err:             err,
+		onInformational: r.onInformational,
+		ctx:             r.ctx,
+		timeout:         r.timeout,
+		retryJitter:     r.retryJitter,
+		proxyAuth:       r.proxyAuth,
+		certificate:     r.certificate,
+		tlsConfig:       r.tlsConfig,
+		insecure:        r.insecure,
+
+		requestBodyTransformers:  slices.Clone(r.requestBodyTransformers),
+		responseBodyTransformers: slices.Clone(r.responseBodyTransformers),
+		onRetry:                  r.onRetry,
+	}
+
+	return
+}
+
+// copyBody copies a body the request would otherwise share, as reusable bodies are rewritten
+// in place as they are read.
+//
+// Parameters:
+//   - body: The body, as given to RequestBuilder.Body or buffered by Clone.
+//
+// Returns:
+//   - copied: A copy of the body, or the body itself if it is immutable.
+func copyBody(body interface{}) (copied interface{}) {
+	copied = body
+
+	switch body := body.(type) {
+	case []byte:
+		copied = bytes.Clone(body)
+	case *[]byte:
+		copied = bytes.Clone(*body)
+	case *hqgoreaderutil.ReusableReadCloser:
+		if content, err := io.ReadAll(body); err == nil {
+			copied = content
+		}
+	}
+
+	return
+}
+
 func (r *RequestBuilder) Build() (req *Request, err error) {
-	req, err = NewRequest(r.method, r._URL, r.body)
+	if r.err != nil {
+		err = r.err
+
+		return
+	}
+
+	URL := r._URL
+
+	params := make([]Param, 0, len(r.defaultParams)+len(r.params))
+
+	for _, key := range slices.Sorted(maps.Keys(r.defaultParams)) {
+		params = append(params, Param{Key: key, Value: r.defaultParams[key], Operation: ParamAdd})
+	}
+
+	params = append(params, r.params...)
+
+	if len(params) > 0 {
+		var parsed *url.URL
+
+		parsed, err = url.Parse(URL)
+		if err != nil {
+			return
+		}
+
+		parsed.RawQuery = applyParams(parsed.RawQuery, params)
+
+		URL = parsed.String()
+	}
+
+	method := r.method
+
+	if method == "" {
+		method = methods.Get
+
+		if r.body != nil {
+			method = methods.Post
+		}
+	}
+
+	method, err = methods.Parse(method.String())
+	if err != nil {
+		return
+	}
+
+	ctx := r.ctx
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if r.timeout > 0 {
+		ctx = context.WithValue(ctx, Timeout, r.timeout)
+	}
+
+	if r.retryJitter != nil {
+		ctx = context.WithValue(ctx, RetryJitter, *r.retryJitter)
+	}
+
+	if r.proxyAuth != nil {
+		ctx = context.WithValue(ctx, ProxyAuth, r.proxyAuth)
+	}
+
+	if r.certificate != nil {
+		ctx = context.WithValue(ctx, ClientCertificate, *r.certificate)
+	}
+
+	if r.tlsConfig != nil {
+		ctx = context.WithValue(ctx, TLSConfig, r.tlsConfig)
+	}
+
+	if r.insecure {
+		ctx = context.WithValue(ctx, InsecureSkipVerify, true)
+	}
+
+	req, err = NewRequestWithContext(ctx, method.String(), URL, r.body)
 	if err != nil {
 		return
 	}
 
-	req.Request.Header = r.header
+	req.Request.Header = r.header.Clone()
+
+	req.requestBodyTransformers = r.requestBodyTransformers
+	req.responseBodyTransformers = r.responseBodyTransformers
+	req.onRetry = r.onRetry
+
+	if r.onInformational != nil {
+		trace := &httptrace.ClientTrace{
+			Got1xxResponse: r.onInformational,
+		}
+
+		req.Request = req.Request.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	}
 
 	return
 }
@@ -52,22 +428,27 @@ func (r *RequestBuilder) Send() (res *http.Response, err error) {
 	return
 }
 
-func NewRequestBuilder(client *Client, method, URL string) (builder *RequestBuilder) {
+// NewRequestBuilder creates a RequestBuilder for the given method and URL, resolved against
+// the client's BaseURL and carrying the client's default headers. An empty method defaults
+// to GET, or POST if a body is set.
+func NewRequestBuilder(client *Client, method methods.Method, URL string) (builder *RequestBuilder) {
 	builder = &RequestBuilder{}
 
 	builder.client = client
 	builder.method = method
 
-	if client.BaseURL != "" {
-		URL = client.BaseURL + URL
-	}
-
-	builder._URL = URL
+	builder._URL, builder.err = joinURL(client.BaseURL, URL)
 	builder.header = make(http.Header)
 
 	for k, v := range client.Headers {
 		builder.header.Set(k, v)
 	}
 
+	builder.defaultParams = make(map[string]string, len(client.Params))
+
+	for k, v := range client.Params {
+		builder.defaultParams[k] = v
+	}
+
 	return
 }
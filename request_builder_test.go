@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"go.source.hueristiq.com/http/methods"
+)
+
+func TestRequestBuilderCloneCopiesEveryField(t *testing.T) {
+	jitter := JitterFull
+
+	original := &RequestBuilder{
+		client:          DefaultClient,
+		method:          methods.Post,
+		_URL:            "https://example.com/",
+		header:          http.Header{"X-Test": {"1"}},
+		params:          []Param{{Key: "q", Value: "1"}},
+		body:            []byte("body"),
+		err:             errors.New("build error"),
+		defaultParams:   map[string]string{"page": "1"},
+		onInformational: func(int, textproto.MIMEHeader) error { return nil },
+		ctx:             context.Background(),
+		timeout:         time.Second,
+		retryJitter:     &jitter,
+		proxyAuth:       &BasicAuth{Username: "user", Password: "password"},
+		certificate:     &tls.Certificate{},
+		tlsConfig:       &tls.Config{MinVersion: tls.VersionTLS13},
+		insecure:        true,
+
+		requestBodyTransformers:  []BodyTransformer{func(_ http.Header, body io.Reader) io.Reader { return body }},
+		responseBodyTransformers: []BodyTransformer{func(_ http.Header, body io.Reader) io.Reader { return body }},
+		onRetry:                  func(*Request, RetryInfo) {},
+	}
+
+	clone := original.Clone()
+
+	originalValue, cloneValue := reflect.ValueOf(original).Elem(), reflect.ValueOf(clone).Elem()
+
+	for i := range originalValue.NumField() {
+		name := originalValue.Type().Field(i).Name
+
+		// The mutex guards the builder itself and is not copied.
+		if name == "mutex" {
+			continue
+		}
+
+		want, got := originalValue.Field(i), cloneValue.Field(i)
+
+		// A zero field would pass unnoticed: every field added to RequestBuilder must be set above.
+		if want.IsZero() {
+			t.Fatalf("field %s is not set in the test", name)
+		}
+
+		if !equalValues(want, got) {
+			t.Errorf("field %s not copied by Clone", name)
+		}
+	}
+}
+
+// equalValues reports whether two addressable struct fields are deeply equal, comparing
+// functions by code pointer.
+func equalValues(a, b reflect.Value) (equal bool) {
+	switch {
+	case a.Kind() == reflect.Func:
+		equal = a.Pointer() == b.Pointer()
+	case a.Kind() == reflect.Slice && a.Type().Elem().Kind() == reflect.Func:
+		equal = a.Len() == b.Len()
+
+		for i := 0; equal && i < a.Len(); i++ {
+			equal = a.Index(i).Pointer() == b.Index(i).Pointer()
+		}
+	default:
+		// Unexported fields can only be read through their address.
+		equal = reflect.DeepEqual(
+			reflect.NewAt(a.Type(), unsafe.Pointer(a.UnsafeAddr())).Elem().Interface(),
+			reflect.NewAt(b.Type(), unsafe.Pointer(b.UnsafeAddr())).Elem().Interface(),
+		)
+	}
+
+	return
+}
+
+func TestRequestBuilderCloneConcurrently(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(w, r.Body)
+	}))
+
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfiguration{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		body func() interface{}
+	}{
+		{
+			name: "bytes",
+			body: func() interface{} { return []byte("body") },
+		},
+		{
+			name: "reader",
+			body: func() interface{} { return io.NopCloser(strings.NewReader("body")) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template := client.POST(server.URL).Body(tt.body())
+
+			var wg sync.WaitGroup
+
+			for range 8 {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					for range 4 {
+						res, err := template.Clone().Send()
+						if err != nil {
+							t.Error(err)
+
+							return
+						}
+
+						body, err := io.ReadAll(res.Body)
+
+						res.Body.Close()
+
+						if err != nil || string(body) != "body" {
+							t.Errorf("got body %q (%v), want %q", body, err, "body")
+						}
+					}
+				}()
+			}
+
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// requestIDContextKey is the context key under which Client.Do stores the ID of a request, so
+// that it can be read back from the response (see ResponseRequestID).
+const requestIDContextKey ContextOverride = "request-id"
+
+// assignRequestID gives a request the ID configured through ClientConfiguration.RequestIDHeader
+// and Traceparent, once per logical request, so that every attempt carries the same ID. IDs
+// set by the caller are kept.
+//
+// Parameters:
+//   - req: The request, updated in place.
+//
+// Returns: None.
+func (c *Client) assignRequestID(req *Request) {
+	if c.cfg.RequestIDHeader == "" && !c.cfg.Traceparent {
+		return
+	}
+
+	id := ""
+
+	if c.cfg.RequestIDHeader != "" {
+		id = req.Header.Get(c.cfg.RequestIDHeader)
+	}
+
+	if id == "" {
+		id = randomHex(16)
+	}
+
+	if c.cfg.RequestIDHeader != "" {
+		req.Header.Set(c.cfg.RequestIDHeader, id)
+	}
+
+	if c.cfg.Traceparent && req.Header.Get(headers.Traceparent.String()) == "" {
+		// The trace ID is the request ID when it is a valid one, so that both correlate.
+		traceID := id
+
+		if decoded, err := hex.DecodeString(id); err != nil || len(decoded) != 16 || id != hex.EncodeToString(decoded) {
+			traceID = randomHex(16)
+		}
+
+		req.Header.Set(headers.Traceparent.String(), fmt.Sprintf("00-%s-%s-01", traceID, randomHex(8)))
+	}
+
+	req.Metrics.RequestID = id
+
+	req.WithContext(context.WithValue(req.Context(), requestIDContextKey, id))
+}
+
+// ResponseRequestID returns the ID Client.Do gave the request of a response, e.g. to
+// correlate it with server logs.
+//
+// Parameters:
+//   - res: The response.
+//
+// Returns:
+//   - id: The ID, empty if the request has none.
+func ResponseRequestID(res *http.Response) (id string) {
+	if res == nil || res.Request == nil {
+		return
+	}
+
+	id, _ = res.Request.Context().Value(requestIDContextKey).(string)
+
+	return
+}
+
+// randomHex returns random bytes encoded as lowercase hexadecimal.
+//
+// Parameters:
+//   - n: The number of random bytes.
+//
+// Returns:
+//   - encoded: The encoded bytes, 2*n characters long.
+func randomHex(n int) (encoded string) {
+	buf := make([]byte, n)
+
+	_, _ = rand.Read(buf)
+
+	encoded = hex.EncodeToString(buf)
+
+	return
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+func TestRequestIDPerSend(t *testing.T) {
+	var ids, traceparents []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(headers.XRequestID.String()))
+		traceparents = append(traceparents, r.Header.Get(headers.Traceparent.String()))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfiguration{
+		Timeout:         5 * time.Second,
+		RequestIDHeader: headers.XRequestID.String(),
+		Traceparent:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	builder := client.GET(server.URL)
+
+	for range 2 {
+		res, err := builder.Send()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res.Body.Close()
+	}
+
+	if ids[0] == "" || ids[0] == ids[1] {
+		t.Fatalf("got request IDs %q, want two distinct ones", ids)
+	}
+
+	if traceparents[0] == "" || traceparents[0] == traceparents[1] {
+		t.Fatalf("got traceparents %q, want two distinct ones", traceparents)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/x509"
 	"errors"
+	"net/http"
 	"net/url"
 	"regexp"
+	"time"
 )
 
 // RetryPolicy defines a function type that determines whether a request should be retried.
@@ -19,12 +21,36 @@ import (
 //
 // Parameters:
 //   - ctx: The request's context, which may contain deadlines or cancellation signals.
+//   - res: The HTTP response returned by the request. Can be nil if the request failed.
 //   - err: The error encountered during the request. Can be nil if the request succeeded.
 //
 // Returns:
 //   - retry: A boolean indicating whether the request should be retried.
 //   - errr: An error if there was an issue while checking for retry logic.
-type RetryPolicy func(ctx context.Context, err error) (retry bool, errr error)
+type RetryPolicy func(ctx context.Context, res *http.Response, err error) (retry bool, errr error)
+
+// RetryInfo describes a retry about to happen.
+type RetryInfo struct {
+	Attempt    int           // Number of the attempt that failed, starting at 1.
+	Wait       time.Duration // Time waited before the next attempt.
+	Err        error         // Error the attempt failed with, nil if it was retried on its status.
+	StatusCode int           // Status of the response of the attempt, zero if it failed with an error.
+}
+
+// RetryHandler defines a function type observing retries, e.g. to log them or emit metrics.
+// It is called after an attempt is deemed retryable, before waiting for the next one.
+//
+// Parameters:
+//   - req: The request being retried.
+//   - info: The retry metadata.
+//
+// Returns: None.
+type RetryHandler func(req *Request, info RetryInfo)
+
+// ErrRetryableStatus marks, within the retry loop, an attempt whose response is retried on its
+// status (see IsStatusRetryable). Once retries are exhausted, the last response is returned
+// instead, for the caller to read.
+var ErrRetryableStatus = errors.New("retryable response status")
 
 var (
 	// redirectsErrorRegex is a regular expression to match the error returned by net/http when the
@@ -45,7 +71,7 @@ var (
 //
 // Returns:
 //   - A RetryPolicy function that determines if the request should be retried.
-func DefaultRetryPolicy() func(ctx context.Context, err error) (retry bool, errr error) {
+func DefaultRetryPolicy() func(ctx context.Context, res *http.Response, err error) (retry bool, errr error) {
 	return IsErrorRecoverable
 }
 
@@ -56,7 +82,7 @@ func DefaultRetryPolicy() func(ctx context.Context, err error) (retry bool, errr
 //
 // Returns:
 //   - A RetryPolicy function that determines if the request should be retried based on recoverable errors.
-func HostSprayRetryPolicy() func(ctx context.Context, err error) (retry bool, errr error) {
+func HostSprayRetryPolicy() func(ctx context.Context, res *http.Response, err error) (retry bool, errr error) {
 	return IsErrorRecoverable
 }
 
@@ -66,12 +92,12 @@ func HostSprayRetryPolicy() func(ctx context.Context, err error) (retry bool, er
 // Parameters:
 //   - ctx: The request's context, which may contain deadlines or cancellation signals.
 //   - res: The HTTP response returned by the request. Can be nil if the request failed.
-//   - target: The error encountered during the request.
+//   - err: The error encountered during the request.
 //
 // Returns:
 //   - recoverable: A boolean indicating whether the error is recoverable and the request can be retried.
 //   - errr: An error if the context encountered an issue (e.g., context.Canceled or context.DeadlineExceeded).
-func IsErrorRecoverable(ctx context.Context, err error) (recoverable bool, errr error) {
+func IsErrorRecoverable(ctx context.Context, res *http.Response, err error) (recoverable bool, errr error) {
 	// Do not retry if the context has been canceled or the deadline has been exceeded
 	if ctx.Err() != nil {
 		errr = ctx.Err()
@@ -79,6 +105,27 @@ func IsErrorRecoverable(ctx context.Context, err error) (recoverable bool, errr
 		return
 	}
 
+	// Do not retry if the server responded too slowly or endlessly, it is likely to do so again (e.g., a tarpit)
+	if errors.Is(err, ErrSlowResponse) || errors.Is(err, ErrTarpit) {
+		errr = err
+
+		return
+	}
+
+	// Do not retry if the response headers exceed the configured limits, they would do so again
+	if errors.Is(err, ErrResponseHeaderTooLarge) || errors.Is(err, ErrTooManyResponseHeaders) {
+		errr = err
+
+		return
+	}
+
+	// Do not retry if the destination is forbidden, it will remain so
+	if errors.Is(err, ErrForbiddenDestination) {
+		errr = err
+
+		return
+	}
+
 	var URLError *url.Error
 
 	if err != nil && errors.As(err, &URLError) {
@@ -112,5 +159,33 @@ func IsErrorRecoverable(ctx context.Context, err error) (recoverable bool, errr
 		return
 	}
 
+	if res != nil && IsStatusRetryable(res.StatusCode) {
+		recoverable = true
+
+		return
+	}
+
+	return
+}
+
+// IsStatusRetryable reports whether a response status denotes a transient condition worth
+// retrying: 429 Too Many Requests, 500 Internal Server Error, 502 Bad Gateway,
+// 503 Service Unavailable and 504 Gateway Timeout.
+//
+// Parameters:
+//   - code: The response status code.
+//
+// Returns:
+//   - retryable: Whether the request should be retried.
+func IsStatusRetryable(code int) (retryable bool) {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		retryable = true
+	}
+
 	return
 }
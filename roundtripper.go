@@ -0,0 +1,60 @@
+package http
+
+import (
+	"net/http"
+
+	hqgoreaderutil "github.com/hueristiq/hqgoutils/reader"
+)
+
+// roundTripper is the http.RoundTripper returned by Client.Transport.
+type roundTripper struct {
+	client *Client
+}
+
+// RoundTrip sends a request through Client.Do. The request is left unmodified, and its body
+// is read up front so that it can be sent again on retries.
+//
+// Parameters:
+//   - r: The request.
+//
+// Returns:
+//   - res: The response.
+//   - err: The error of Client.Do.
+func (t *roundTripper) RoundTrip(r *http.Request) (res *http.Response, err error) {
+	clone := r.Clone(r.Context())
+
+	if r.Body != nil && r.Body != http.NoBody {
+		var body *hqgoreaderutil.ReusableReadCloser
+
+		body, err = hqgoreaderutil.NewReusableReadCloser(r.Body)
+
+		// RoundTrippers must close the body, including on errors.
+		r.Body.Close()
+
+		if err != nil {
+			return
+		}
+
+		clone.Body = body
+		clone.GetBody = nil
+	}
+
+	res, err = t.client.Do(&Request{Request: clone})
+
+	return
+}
+
+// Transport returns an http.RoundTripper sending requests through the client, so that
+// third-party SDKs only accepting an *http.Client get its retries, authentication, hooks and
+// metrics (e.g., &http.Client{Transport: client.Transport()}). The client must not itself use
+// an HTTP client built on this transport.
+//
+// Parameters: None.
+//
+// Returns:
+//   - transport: The transport.
+func (c *Client) Transport() (transport http.RoundTripper) {
+	transport = &roundTripper{client: c}
+
+	return
+}
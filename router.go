@@ -0,0 +1,239 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// ErrNoResponseHandler is returned by ResponseRouter.Dispatch for responses whose media type
+// matches no registered pattern, when no fallback handler is set.
+var ErrNoResponseHandler = errors.New("no response handler")
+
+// sniffLength is the number of body bytes inspected to detect the media type of responses
+// without a Content-Type header, as by http.DetectContentType.
+const sniffLength = 512
+
+// ResponseHandler defines a function type handling a response routed by a ResponseRouter,
+// e.g. decoding JSON, scraping HTML or saving binary content.
+//
+// Parameters:
+//   - res: The response. Its body is closed by the router once the handler returns.
+//   - mediaType: The lowercased media type of the response, without parameters
+//     (e.g., "application/json").
+//
+// Returns:
+//   - err: The error reported by ResponseRouter.Dispatch.
+type ResponseHandler func(res *http.Response, mediaType string) (err error)
+
+// responseRoute is a media type pattern and the handler of the responses it matches.
+type responseRoute struct {
+	typ     string
+	subtype string
+	handler ResponseHandler
+}
+
+// specificity ranks the route against others matching the same media type: exact types before
+// wildcards, and exact subtypes before structured syntax suffixes before wildcards.
+//
+// Parameters: None.
+//
+// Returns:
+//   - rank: The rank, higher for more specific patterns.
+func (r responseRoute) specificity() (rank int) {
+	if r.typ != "*" {
+		rank += 4
+	}
+
+	switch {
+	case strings.HasPrefix(r.subtype, "*+"):
+		rank++
+	case r.subtype != "*":
+		rank += 2
+	}
+
+	return
+}
+
+// matches reports whether the route matches a media type.
+//
+// Parameters:
+//   - typ: The type of the media type (e.g., "application").
+//   - subtype: The subtype of the media type (e.g., "ld+json").
+//
+// Returns:
+//   - matches: Whether the route matches.
+func (r responseRoute) matches(typ, subtype string) (matches bool) {
+	if r.typ != "*" && r.typ != typ {
+		return
+	}
+
+	switch {
+	case r.subtype == "*":
+		matches = true
+	case strings.HasPrefix(r.subtype, "*+"):
+		matches = strings.HasSuffix(subtype, r.subtype[1:])
+	default:
+		matches = r.subtype == subtype
+	}
+
+	return
+}
+
+// ResponseRouter routes responses to handlers by media type, e.g. to build crawler pipelines
+// decoding JSON, scraping HTML and saving everything else. The most specific matching pattern
+// wins, ties going to the first registered. Responses without a Content-Type header are
+// routed by the media type sniffed from their body. It is safe for concurrent use.
+type ResponseRouter struct {
+	mutex    sync.RWMutex
+	routes   []responseRoute
+	fallback ResponseHandler
+}
+
+// Handle registers the handler of responses whose media type matches a pattern: a media type
+// (e.g., "application/json"), a type wildcard (e.g., "text/*"), a structured syntax suffix
+// wildcard (e.g., "application/*+json", "*/*+xml"), or "*/*". Patterns are case-insensitive.
+// Like http.ServeMux, Handle panics on a pattern that is not of the "type/subtype" form, or a
+// nil handler.
+//
+// Parameters:
+//   - pattern: The media type pattern.
+//   - handler: The handler.
+//
+// Returns:
+//   - router: The router, for chaining.
+func (r *ResponseRouter) Handle(pattern string, handler ResponseHandler) (router *ResponseRouter) {
+	router = r
+
+	typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(pattern)), "/")
+	if !ok || typ == "" || subtype == "" {
+		panic(fmt.Sprintf("http: invalid media type pattern %q", pattern))
+	}
+
+	if handler == nil {
+		panic("http: nil response handler")
+	}
+
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.routes = append(r.routes, responseRoute{
+		typ:     typ,
+		subtype: subtype,
+		handler: handler,
+	})
+
+	return
+}
+
+// Fallback sets the handler of responses matching no pattern.
+//
+// Parameters:
+//   - handler: The handler, nil to report ErrNoResponseHandler instead.
+//
+// Returns:
+//   - router: The router, for chaining.
+func (r *ResponseRouter) Fallback(handler ResponseHandler) (router *ResponseRouter) {
+	router = r
+
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.fallback = handler
+
+	return
+}
+
+// Dispatch routes a response to the handler of its media type, closing its body once the
+// handler returns.
+//
+// Parameters:
+//   - res: The response.
+//
+// Returns:
+//   - err: The error of the handler, or an error wrapping ErrNoResponseHandler if no handler
+//     matches.
+func (r *ResponseRouter) Dispatch(res *http.Response) (err error) {
+	defer res.Body.Close()
+
+	mediaType, err := responseMediaType(res)
+	if err != nil {
+		return
+	}
+
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+
+	r.mutex.RLock()
+
+	handler := r.fallback
+	rank := -1
+
+	for _, route := range r.routes {
+		if specificity := route.specificity(); specificity > rank && route.matches(typ, subtype) {
+			handler, rank = route.handler, specificity
+		}
+	}
+
+	r.mutex.RUnlock()
+
+	if handler == nil {
+		err = fmt.Errorf("%w: %s", ErrNoResponseHandler, mediaType)
+
+		return
+	}
+
+	err = handler(res, mediaType)
+
+	return
+}
+
+// responseMediaType returns the media type of a response, sniffed from the first bytes of its
+// body if it has no Content-Type header. Malformed Content-Type headers are treated as
+// "application/octet-stream".
+//
+// Parameters:
+//   - res: The response, whose body is replaced if sniffed.
+//
+// Returns:
+//   - mediaType: The lowercased media type, without parameters.
+//   - err: An error if the body cannot be read.
+func responseMediaType(res *http.Response) (mediaType string, err error) {
+	contentType := res.Header.Get(headers.ContentType.String())
+
+	if contentType == "" {
+		var prefix []byte
+
+		prefix, res.Body, err = readBodyPrefix(res.Body, sniffLength)
+		if err != nil {
+			return
+		}
+
+		contentType = http.DetectContentType(prefix)
+	}
+
+	mediaType, _, errr := mime.ParseMediaType(contentType)
+	if errr != nil || !strings.Contains(mediaType, "/") {
+		mediaType = "application/octet-stream"
+	}
+
+	return
+}
+
+// NewResponseRouter creates a ResponseRouter without handlers.
+//
+// Parameters: None.
+//
+// Returns:
+//   - router: The new router.
+func NewResponseRouter() (router *ResponseRouter) {
+	router = &ResponseRouter{}
+
+	return
+}
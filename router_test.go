@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestResponseRouterHandlePanicsOnInvalidPattern(t *testing.T) {
+	handler := func(*http.Response, string) error { return nil }
+
+	tests := []struct {
+		name      string
+		pattern   string
+		handler   ResponseHandler
+		wantPanic bool
+	}{
+		{
+			name:    "media type",
+			pattern: "application/json",
+			handler: handler,
+		},
+		{
+			name:    "suffix wildcard",
+			pattern: "*/*+xml",
+			handler: handler,
+		},
+		{
+			name:      "missing subtype",
+			pattern:   "json",
+			handler:   handler,
+			wantPanic: true,
+		},
+		{
+			name:      "empty subtype",
+			pattern:   "text/",
+			handler:   handler,
+			wantPanic: true,
+		},
+		{
+			name:      "nil handler",
+			pattern:   "text/html",
+			wantPanic: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recovered := recover(); (recovered != nil) != tt.wantPanic {
+					t.Fatalf("got panic %v, want panic %t", recovered, tt.wantPanic)
+				}
+			}()
+
+			(&ResponseRouter{}).Handle(tt.pattern, tt.handler)
+		})
+	}
+}
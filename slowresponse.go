@@ -0,0 +1,448 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMinRateWindow is the window over which response rates are measured when
+// ClientConfiguration.MinRateWindow is not set.
+const DefaultMinRateWindow = 5 * time.Second
+
+// ErrSlowResponse is returned when the headers or the body of a response arrive slower than
+// the minimum rate configured through ClientConfiguration.MinHeaderRate or MinBodyRate,
+// as done by slowloris-style servers and tarpits.
+var ErrSlowResponse = errors.New("response slower than minimum rate")
+
+// ErrTarpit is returned when a response body grows past ClientConfiguration.MaxBodyBytes or
+// keeps streaming past MaxBodyDuration, as done by tarpits serving endless (e.g., chunked) bodies.
+var ErrTarpit = errors.New("tarpit detected: unbounded response body")
+
+// countingConn is a net.Conn counting the bytes read from it.
+type countingConn struct {
+	net.Conn
+
+	read atomic.Int64
+}
+
+// Read reads from the connection and counts the bytes read.
+func (c *countingConn) Read(p []byte) (n int, err error) {
+	n, err = c.Conn.Read(p)
+
+	c.read.Add(int64(n))
+
+	return
+}
+
+// countingDialContext wraps a dial function so that the connections it establishes count the
+// bytes read from them.
+//
+// Parameters:
+//   - dial: The dial function to wrap.
+//
+// Returns:
+//   - wrapped: The wrapping dial function.
+func countingDialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) (wrapped func(ctx context.Context, network, address string) (net.Conn, error)) {
+	wrapped = func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		conn, err = dial(ctx, network, address)
+		if err != nil {
+			return
+		}
+
+		conn = &countingConn{Conn: conn}
+
+		return
+	}
+
+	return
+}
+
+// bytesReadCounter returns the read counter of a connection established through
+// countingDialContext, unwrapping TLS connections.
+//
+// Parameters:
+//   - conn: The connection, as reported by httptrace.GotConnInfo.
+//
+// Returns:
+//   - counter: The read counter.
+//   - ok: Whether the connection counts the bytes read from it.
+func bytesReadCounter(conn net.Conn) (counter *atomic.Int64, ok bool) {
+	if wrapper, isWrapper := conn.(interface{ NetConn() net.Conn }); isWrapper {
+		conn = wrapper.NetConn()
+	}
+
+	counting, ok := conn.(*countingConn)
+	if !ok {
+		return
+	}
+
+	counter = &counting.read
+
+	return
+}
+
+// rateMonitor aborts an attempt whose response headers or body arrive slower than a minimum
+// rate. Progress is sampled once per window: once a window of waiting on the server has
+// elapsed, if fewer bytes than the minimum rate allows arrived during it, the attempt context
+// is canceled with ErrSlowResponse. Bodies growing or streaming past their limits are
+// canceled with ErrTarpit.
+type rateMonitor struct {
+	minHeaderRate   int64
+	minBodyRate     int64
+	maxBodyBytes    int64
+	maxBodyDuration time.Duration
+	window          time.Duration
+	metrics         *Metrics
+	ctx             context.Context //nolint:containedctx // The attempt context, to tell why it was canceled.
+	cancel          context.CancelCauseFunc
+	done            chan struct{}
+	stopOnce        sync.Once
+	bodyDeadline    *time.Timer
+
+	mutex    sync.Mutex
+	conn     *atomic.Int64
+	phase    int
+	progress func() (n int64, waited time.Duration)
+	minRate  int64
+}
+
+// trace returns the httptrace.ClientTrace starting the header phase once the request is written.
+//
+// Parameters: None.
+//
+// Returns:
+//   - trace: The client trace.
+func (m *rateMonitor) trace() (trace *httptrace.ClientTrace) {
+	trace = &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if counter, ok := bytesReadCounter(info.Conn); ok {
+				m.mutex.Lock()
+				m.conn = counter
+				m.mutex.Unlock()
+			}
+		},
+		WroteRequest: func(_ httptrace.WroteRequestInfo) {
+			m.mutex.Lock()
+
+			defer m.mutex.Unlock()
+
+			// Header bytes can only be counted on connections dialed through countingDialContext.
+			if m.conn == nil {
+				return
+			}
+
+			counter, start := m.conn, time.Now()
+
+			m.startPhase(func() (n int64, waited time.Duration) {
+				return counter.Load(), time.Since(start)
+			}, m.minHeaderRate)
+		},
+	}
+
+	return
+}
+
+// startPhase makes the monitor measure a new phase. The caller must hold the lock.
+//
+// Parameters:
+//   - progress: Returns the bytes received so far in the phase, and the time spent waiting
+//     on the server for them.
+//   - minRate: The minimum rate of the phase, in bytes per second. Zero disables the check.
+//
+// Returns: None.
+func (m *rateMonitor) startPhase(progress func() (n int64, waited time.Duration), minRate int64) {
+	m.phase++
+
+	m.progress = nil
+
+	if minRate > 0 {
+		m.progress = progress
+		m.minRate = minRate
+	}
+}
+
+// watch samples the progress of the current phase once per window until the monitor stops.
+//
+// Parameters: None.
+//
+// Returns: None.
+func (m *rateMonitor) watch() {
+	ticker := time.NewTicker(m.window)
+
+	defer ticker.Stop()
+
+	var (
+		last       int64
+		lastWaited time.Duration
+	)
+
+	phase := 0
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+		}
+
+		m.mutex.Lock()
+
+		currentPhase, progress, minRate := m.phase, m.progress, m.minRate
+
+		m.mutex.Unlock()
+
+		if progress == nil {
+			continue
+		}
+
+		current, waited := progress()
+
+		// The phase started during the last window: its first full window starts now.
+		if currentPhase != phase {
+			phase, last, lastWaited = currentPhase, current, waited
+
+			continue
+		}
+
+		// Time not spent waiting on the server (e.g., a caller pausing between reads of the
+		// body) does not count towards the window.
+		if waited-lastWaited < m.window {
+			continue
+		}
+
+		if float64(current-last)/(waited-lastWaited).Seconds() < float64(minRate) {
+			m.cancel(ErrSlowResponse)
+
+			return
+		}
+
+		last, lastWaited = current, waited
+	}
+}
+
+// watchBody makes the monitor measure the body phase of a response, wrapping its body so
+// that the monitor stops once the body is fully read or closed. The body rate is measured
+// over the time reads of the body are pending, so that callers processing the body between
+// reads are not taken for slow servers.
+//
+// Parameters:
+//   - res: The response whose body is monitored.
+//
+// Returns: None.
+func (m *rateMonitor) watchBody(res *http.Response) {
+	body := &monitoredBody{ReadCloser: res.Body, monitor: m}
+
+	m.mutex.Lock()
+
+	m.startPhase(body.progress, m.minBodyRate)
+
+	if m.maxBodyDuration > 0 {
+		m.bodyDeadline = time.AfterFunc(m.maxBodyDuration, func() {
+			m.cancel(ErrTarpit)
+		})
+	}
+
+	m.mutex.Unlock()
+
+	res.Body = body
+}
+
+// settle ends the header phase of an attempt: the body of a response is monitored next,
+// while a failed attempt stops the monitor. A nil monitor does nothing.
+//
+// Parameters:
+//   - res: The response of the attempt, nil if it failed.
+//   - err: The error the attempt failed with.
+//
+// Returns: None.
+func (m *rateMonitor) settle(res *http.Response, err error) {
+	if m == nil {
+		return
+	}
+
+	if err != nil || res == nil {
+		m.stop()
+
+		return
+	}
+
+	m.watchBody(res)
+}
+
+// stop stops the monitor and releases the attempt context.
+//
+// Parameters: None.
+//
+// Returns: None.
+func (m *rateMonitor) stop() {
+	m.stopOnce.Do(func() {
+		close(m.done)
+
+		m.mutex.Lock()
+
+		if m.bodyDeadline != nil {
+			m.bodyDeadline.Stop()
+		}
+
+		m.mutex.Unlock()
+
+		m.cancel(nil)
+	})
+}
+
+// err returns ErrSlowResponse or ErrTarpit, wrapping err, if the monitor aborted the attempt,
+// and flags the attempt in Metrics.Tarpit. A nil monitor returns err unchanged.
+//
+// Parameters:
+//   - err: The error the attempt failed with.
+//
+// Returns:
+//   - errr: The error to report.
+func (m *rateMonitor) err(err error) (errr error) {
+	errr = err
+
+	if m == nil || err == nil {
+		return
+	}
+
+	cause := context.Cause(m.ctx)
+
+	if !errors.Is(cause, ErrSlowResponse) && !errors.Is(cause, ErrTarpit) {
+		return
+	}
+
+	m.metrics.Tarpit = true
+
+	if !errors.Is(err, cause) {
+		errr = fmt.Errorf("%w: %w", cause, err)
+	}
+
+	return
+}
+
+// monitoredBody is a response body whose bytes feed a rateMonitor.
+type monitoredBody struct {
+	io.ReadCloser
+
+	monitor *rateMonitor
+	read    atomic.Int64
+
+	mutex        sync.Mutex
+	waited       time.Duration
+	pendingSince time.Time
+}
+
+// progress returns the bytes read from the body and the time reads of the body were
+// pending, including the pending read if any.
+//
+// Parameters: None.
+//
+// Returns:
+//   - n: The bytes read.
+//   - waited: The time reads were pending.
+func (b *monitoredBody) progress() (n int64, waited time.Duration) {
+	b.mutex.Lock()
+
+	defer b.mutex.Unlock()
+
+	n, waited = b.read.Load(), b.waited
+
+	if !b.pendingSince.IsZero() {
+		waited += time.Since(b.pendingSince)
+	}
+
+	return
+}
+
+// Read reads from the body, stopping the monitor once the body is fully read.
+func (b *monitoredBody) Read(p []byte) (n int, err error) {
+	b.mutex.Lock()
+
+	b.pendingSince = time.Now()
+
+	b.mutex.Unlock()
+
+	n, err = b.ReadCloser.Read(p)
+
+	b.mutex.Lock()
+
+	read := b.read.Add(int64(n))
+
+	b.waited += time.Since(b.pendingSince)
+	b.pendingSince = time.Time{}
+
+	b.mutex.Unlock()
+
+	if b.monitor.maxBodyBytes > 0 && read > b.monitor.maxBodyBytes {
+		b.monitor.cancel(ErrTarpit)
+
+		err = ErrTarpit
+	}
+
+	if errors.Is(err, io.EOF) {
+		b.monitor.stop()
+
+		return
+	}
+
+	err = b.monitor.err(err)
+
+	return
+}
+
+// Close closes the body and stops the monitor.
+func (b *monitoredBody) Close() (err error) {
+	err = b.ReadCloser.Close()
+
+	b.monitor.stop()
+
+	return
+}
+
+// withRateMonitor derives an attempt context monitored for slow responses and unbounded
+// bodies, or returns ctx unchanged and a nil monitor if no limit is configured.
+//
+// Parameters:
+//   - ctx: The attempt context.
+//   - metrics: The metrics of the request, flagged if the attempt is aborted.
+//
+// Returns:
+//   - monitored: The monitored context.
+//   - monitor: The monitor, nil if monitoring is disabled.
+func (c *Client) withRateMonitor(ctx context.Context, metrics *Metrics) (monitored context.Context, monitor *rateMonitor) {
+	monitored = ctx
+
+	if c.cfg.MinHeaderRate <= 0 && c.cfg.MinBodyRate <= 0 && c.cfg.MaxBodyBytes <= 0 && c.cfg.MaxBodyDuration <= 0 {
+		return
+	}
+
+	monitor = &rateMonitor{
+		minHeaderRate:   c.cfg.MinHeaderRate,
+		minBodyRate:     c.cfg.MinBodyRate,
+		maxBodyBytes:    c.cfg.MaxBodyBytes,
+		maxBodyDuration: c.cfg.MaxBodyDuration,
+		window:          c.cfg.MinRateWindow,
+		metrics:         metrics,
+		done:            make(chan struct{}),
+	}
+
+	if monitor.window <= 0 {
+		monitor.window = DefaultMinRateWindow
+	}
+
+	monitor.ctx, monitor.cancel = context.WithCancelCause(ctx)
+
+	monitored = httptrace.WithClientTrace(monitor.ctx, monitor.trace())
+
+	go monitor.watch()
+
+	return
+}
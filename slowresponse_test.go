@@ -0,0 +1,74 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMinBodyRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverPause time.Duration
+		readerPause time.Duration
+		wantErr     error
+	}{
+		{
+			name:        "caller pausing between reads",
+			readerPause: 500 * time.Millisecond,
+		},
+		{
+			name:        "server stalling the body",
+			serverPause: 500 * time.Millisecond,
+			wantErr:     ErrSlowResponse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+
+				_, _ = io.WriteString(w, strings.Repeat("a", 4<<10))
+
+				w.(http.Flusher).Flush()
+
+				time.Sleep(tt.serverPause)
+
+				_, _ = io.WriteString(w, "end")
+			}))
+
+			defer server.Close()
+
+			client, err := NewClient(&ClientConfiguration{
+				Timeout:       5 * time.Second,
+				MinBodyRate:   100,
+				MinRateWindow: 100 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := client.GET(server.URL).Send()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer res.Body.Close()
+
+			if _, err = io.ReadFull(res.Body, make([]byte, 1<<10)); err != nil {
+				t.Fatal(err)
+			}
+
+			time.Sleep(tt.readerPause)
+
+			if _, err = io.ReadAll(res.Body); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package http
+
+import (
+	"crypto/tls"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ConfigSnapshot is a serializable view of the effective configuration of a client, for
+// embedding in scan reports and reproducibility records. It is sanitized: credentials are only
+// reported as configured, the values of SensitiveHeaders are redacted, query parameters are
+// reported by name and URL passwords are redacted. Durations are formatted as by
+// time.Duration.String.
+type ConfigSnapshot struct {
+	BaseURL string            `json:"baseURL,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"` // Default headers, with the values of SensitiveHeaders redacted.
+	Params  []string          `json:"params,omitempty"`  // Names of the default query parameters, whose values may carry credentials.
+
+	Timeout      string `json:"timeout"`      // Timeout of the HTTP/1.x client, once adjusted for retries.
+	HTTP2Timeout string `json:"http2Timeout"` // Timeout of the HTTP/2 client.
+	HedgeDelay   string `json:"hedgeDelay,omitempty"`
+
+	FallbackPolicy string `json:"fallbackPolicy"`
+
+	Retry      RetrySnapshot      `json:"retry"`
+	Proxy      ProxySnapshot      `json:"proxy"`
+	TLS        TLSSnapshot        `json:"tls"`
+	Middleware MiddlewareSnapshot `json:"middleware"`
+	Security   SecuritySnapshot   `json:"security"`
+	Limits     LimitsSnapshot     `json:"limits"`
+}
+
+// RetrySnapshot is the retry configuration of a ConfigSnapshot.
+type RetrySnapshot struct {
+	Retries          int               `json:"retries"`
+	WaitMin          string            `json:"waitMin"`
+	WaitMax          string            `json:"waitMax"`
+	Jitter           string            `json:"jitter,omitempty"`
+	Backoff          string            `json:"backoff,omitempty"` // Name of the backoff function.
+	Policy           string            `json:"policy,omitempty"`  // Name of the retry policy function.
+	Profile          string            `json:"profile,omitempty"` // Name of the RetryProfiles entry the settings above come from, if any.
+	HostProfiles     map[string]string `json:"hostProfiles,omitempty"`
+	RetryAfterMax    string            `json:"retryAfterMax,omitempty"`
+	IgnoreRetryAfter bool              `json:"ignoreRetryAfter,omitempty"`
+}
+
+// ProxySnapshot is the proxy configuration of a ConfigSnapshot.
+type ProxySnapshot struct {
+	Enabled           bool              `json:"enabled"` // Whether the transport of the HTTP/1.x client has a proxy function.
+	IgnoreEnvironment bool              `json:"ignoreEnvironment,omitempty"`
+	Environment       map[string]string `json:"environment,omitempty"`   // Proxy environment variables, passwords redacted, unless ignored.
+	Authenticated     bool              `json:"authenticated,omitempty"` // Whether ClientConfiguration.ProxyAuth is set.
+}
+
+// TLSSnapshot is the TLS configuration of a ConfigSnapshot.
+type TLSSnapshot struct {
+	MinVersion                string   `json:"minVersion,omitempty"`
+	MaxVersion                string   `json:"maxVersion,omitempty"`
+	CipherSuites              []string `json:"cipherSuites,omitempty"`
+	ServerName                string   `json:"serverName,omitempty"`
+	InsecureSkipVerify        bool     `json:"insecureSkipVerify"`
+	RootCAs                   bool     `json:"rootCAs,omitempty"` // Whether the system roots are replaced.
+	CABundlePaths             []string `json:"caBundlePaths,omitempty"`
+	CABundleReloadInterval    string   `json:"caBundleReloadInterval,omitempty"`
+	ClientCertificates        int      `json:"clientCertificates,omitempty"`
+	ClientCertificateCallback bool     `json:"clientCertificateCallback,omitempty"` // Whether GetClientCertificate is set.
+}
+
+// MiddlewareSnapshot names the hooks, transformers and authentication of a ConfigSnapshot.
+// Functions are named as by the runtime (e.g., "main.audit" or "main.main.func1"), other
+// values by type.
+type MiddlewareSnapshot struct {
+	Hooks                    map[string]string `json:"hooks,omitempty"` // Per hook (e.g., "OnRequest") names of the configured hooks.
+	RequestBodyTransformers  []string          `json:"requestBodyTransformers,omitempty"`
+	ResponseBodyTransformers []string          `json:"responseBodyTransformers,omitempty"`
+	AuthSchemes              []string          `json:"authSchemes,omitempty"` // Schemes answering challenges, in order of preference.
+	Credentials              []string          `json:"credentials,omitempty"` // Kinds of credentials sent (e.g., "BasicAuth"), without their values.
+}
+
+// SecuritySnapshot is the destination filtering of a ConfigSnapshot.
+type SecuritySnapshot struct {
+	SSRFProtection  bool              `json:"ssrfProtection"`
+	DNSPinning      bool              `json:"dnsPinning"`
+	AllowedSchemes  []string          `json:"allowedSchemes,omitempty"`
+	AllowedPorts    []string          `json:"allowedPorts,omitempty"` // Port ranges, formatted as "from-to" or "port".
+	StaticHosts     map[string]string `json:"staticHosts,omitempty"`
+	KeepURLUserinfo bool              `json:"keepURLUserinfo,omitempty"`
+}
+
+// LimitsSnapshot is the response limits of a ConfigSnapshot, zero meaning disabled.
+type LimitsSnapshot struct {
+	MaxBodyBytes           int64   `json:"maxBodyBytes,omitempty"`
+	MaxBodyDuration        string  `json:"maxBodyDuration,omitempty"`
+	MaxDecompressedBytes   int64   `json:"maxDecompressedBytes,omitempty"`
+	MaxDecompressionRatio  float64 `json:"maxDecompressionRatio,omitempty"`
+	MaxResponseHeaderBytes int64   `json:"maxResponseHeaderBytes,omitempty"`
+	MaxResponseHeaders     int     `json:"maxResponseHeaders,omitempty"`
+	MinHeaderRate          int64   `json:"minHeaderRate,omitempty"`
+	MinBodyRate            int64   `json:"minBodyRate,omitempty"`
+	RespReadLimit          int64   `json:"respReadLimit,omitempty"`
+}
+
+// ConfigSnapshot returns a sanitized view of the effective configuration of the client,
+// reflecting the exported fields of the client as they are now, ready to be marshaled to JSON.
+//
+// Parameters: None.
+//
+// Returns:
+//   - snapshot: The snapshot.
+func (c *Client) ConfigSnapshot() (snapshot ConfigSnapshot) {
+	cfg := c.cfg
+
+	snapshot.BaseURL = redactURL(c.BaseURL)
+
+	if len(c.Headers) > 0 {
+		snapshot.Headers = make(map[string]string, len(c.Headers))
+
+		for key, value := range c.Headers {
+			if slices.ContainsFunc(SensitiveHeaders, func(sensitive string) bool {
+				return strings.EqualFold(sensitive, key)
+			}) {
+				value = redactedValue
+			}
+
+			snapshot.Headers[key] = value
+		}
+	}
+
+	if len(c.Params) > 0 {
+		snapshot.Params = slices.Sorted(maps.Keys(c.Params))
+	}
+
+	snapshot.Timeout = c.HTTPClient.Timeout.String()
+	snapshot.HTTP2Timeout = c.HTTP2Client.Timeout.String()
+
+	if cfg.HedgeDelay > 0 {
+		snapshot.HedgeDelay = cfg.HedgeDelay.String()
+	}
+
+	snapshot.FallbackPolicy = cfg.FallbackPolicy.String()
+
+	profile := c.namedRetryProfile(cfg.RetryProfile)
+
+	snapshot.Retry = RetrySnapshot{
+		Retries:          profile.Retries,
+		WaitMin:          profile.RetryWaitMin.String(),
+		WaitMax:          profile.RetryWaitMax.String(),
+		Jitter:           string(profile.RetryJitter),
+		Backoff:          funcName(profile.RetryBackoff),
+		Policy:           funcName(c.RetryPolicy),
+		HostProfiles:     maps.Clone(cfg.HostRetryProfiles),
+		IgnoreRetryAfter: cfg.IgnoreRetryAfter,
+	}
+
+	if _, ok := RetryProfiles[cfg.RetryProfile]; ok {
+		snapshot.Retry.Profile = cfg.RetryProfile
+	}
+
+	if cfg.RetryAfterMax > 0 {
+		snapshot.Retry.RetryAfterMax = cfg.RetryAfterMax.String()
+	}
+
+	snapshot.Proxy = c.proxySnapshot()
+	snapshot.TLS = tlsSnapshot(&cfg.TLS)
+	snapshot.Middleware = c.middlewareSnapshot()
+
+	snapshot.Security = SecuritySnapshot{
+		SSRFProtection:  cfg.SSRFProtection != nil,
+		DNSPinning:      cfg.DNSPinning != nil,
+		AllowedSchemes:  slices.Clone(cfg.AllowedSchemes),
+		StaticHosts:     maps.Clone(cfg.StaticHosts),
+		KeepURLUserinfo: cfg.KeepURLUserinfo,
+	}
+
+	for _, ports := range cfg.AllowedPorts {
+		formatted := strconv.Itoa(int(ports.From))
+
+		if ports.To > ports.From {
+			formatted += "-" + strconv.Itoa(int(ports.To))
+		}
+
+		snapshot.Security.AllowedPorts = append(snapshot.Security.AllowedPorts, formatted)
+	}
+
+	snapshot.Limits = LimitsSnapshot{
+		MaxBodyBytes:           cfg.MaxBodyBytes,
+		MaxDecompressedBytes:   cfg.MaxDecompressedBytes,
+		MaxDecompressionRatio:  cfg.MaxDecompressionRatio,
+		MaxResponseHeaderBytes: cfg.MaxResponseHeaderBytes,
+		MaxResponseHeaders:     cfg.MaxResponseHeaders,
+		MinHeaderRate:          cfg.MinHeaderRate,
+		MinBodyRate:            cfg.MinBodyRate,
+		RespReadLimit:          cfg.RespReadLimit,
+	}
+
+	if cfg.MaxBodyDuration > 0 {
+		snapshot.Limits.MaxBodyDuration = cfg.MaxBodyDuration.String()
+	}
+
+	return
+}
+
+// proxySnapshot returns the proxy configuration of the client.
+//
+// Parameters: None.
+//
+// Returns:
+//   - snapshot: The proxy configuration.
+func (c *Client) proxySnapshot() (snapshot ProxySnapshot) {
+	snapshot.IgnoreEnvironment = c.cfg.IgnoreProxyEnvironment
+	snapshot.Authenticated = c.cfg.ProxyAuth != nil
+
+	transport, ok := c.HTTPClient.Transport.(*http.Transport)
+
+	snapshot.Enabled = ok && transport.Proxy != nil
+
+	if !snapshot.Enabled || snapshot.IgnoreEnvironment {
+		return
+	}
+
+	environment := httpproxy.FromEnvironment()
+
+	for variable, value := range map[string]string{
+		"HTTP_PROXY":  redactProxyURL(environment.HTTPProxy),
+		"HTTPS_PROXY": redactProxyURL(environment.HTTPSProxy),
+		"NO_PROXY":    environment.NoProxy,
+	} {
+		if value == "" {
+			continue
+		}
+
+		if snapshot.Environment == nil {
+			snapshot.Environment = make(map[string]string)
+		}
+
+		snapshot.Environment[variable] = value
+	}
+
+	return
+}
+
+// tlsSnapshot returns a TLS configuration, without its certificates and keys.
+//
+// Parameters:
+//   - config: The TLS configuration.
+//
+// Returns:
+//   - snapshot: The TLS configuration.
+func tlsSnapshot(config *TLSConfiguration) (snapshot TLSSnapshot) {
+	snapshot = TLSSnapshot{
+		ServerName:                config.ServerName,
+		InsecureSkipVerify:        config.InsecureSkipVerify,
+		RootCAs:                   config.RootCAs != nil,
+		CABundlePaths:             slices.Clone(config.CABundlePaths),
+		ClientCertificates:        len(config.ClientCertificates),
+		ClientCertificateCallback: config.GetClientCertificate != nil,
+	}
+
+	if config.MinVersion != 0 {
+		snapshot.MinVersion = tls.VersionName(config.MinVersion)
+	}
+
+	if config.MaxVersion != 0 {
+		snapshot.MaxVersion = tls.VersionName(config.MaxVersion)
+	}
+
+	for _, suite := range config.CipherSuites {
+		snapshot.CipherSuites = append(snapshot.CipherSuites, tls.CipherSuiteName(suite))
+	}
+
+	if config.CABundleReloadInterval > 0 {
+		snapshot.CABundleReloadInterval = config.CABundleReloadInterval.String()
+	}
+
+	return
+}
+
+// middlewareSnapshot returns the hooks, transformers and authentication of the client.
+//
+// Parameters: None.
+//
+// Returns:
+//   - snapshot: The hooks, transformers and authentication.
+func (c *Client) middlewareSnapshot() (snapshot MiddlewareSnapshot) {
+	cfg := c.cfg
+
+	hooks := map[string]string{
+		"OnRequest":      funcName(cfg.OnRequest),
+		"OnResponse":     funcName(cfg.OnResponse),
+		"OnAttemptError": funcName(cfg.OnAttemptError),
+		"OnRetry":        funcName(c.OnRetry),
+		"OnError":        funcName(c.OnError),
+		"DumpRequest":    funcName(cfg.DumpRequest),
+		"DumpResponse":   funcName(cfg.DumpResponse),
+	}
+
+	if cfg.Logger != nil {
+		hooks["Logger"] = fmt.Sprintf("%T", cfg.Logger)
+	}
+
+	if cfg.HARRecorder != nil {
+		hooks["HARRecorder"] = fmt.Sprintf("%T", cfg.HARRecorder)
+	}
+
+	maps.DeleteFunc(hooks, func(_, name string) bool {
+		return name == ""
+	})
+
+	if len(hooks) > 0 {
+		snapshot.Hooks = hooks
+	}
+
+	for _, transformer := range cfg.RequestBodyTransformers {
+		snapshot.RequestBodyTransformers = append(snapshot.RequestBodyTransformers, funcName(transformer))
+	}
+
+	for _, transformer := range cfg.ResponseBodyTransformers {
+		snapshot.ResponseBodyTransformers = append(snapshot.ResponseBodyTransformers, funcName(transformer))
+	}
+
+	for _, scheme := range c.authSchemes {
+		snapshot.AuthSchemes = append(snapshot.AuthSchemes, scheme.Scheme())
+	}
+
+	for credentials, configured := range map[string]bool{
+		"BasicAuth":     cfg.BasicAuth != nil,
+		"TokenProvider": cfg.TokenProvider != nil,
+		"DPoP":          cfg.DPoP != nil,
+	} {
+		if configured {
+			snapshot.Credentials = append(snapshot.Credentials, credentials)
+		}
+	}
+
+	slices.Sort(snapshot.Credentials)
+
+	return
+}
+
+// funcName returns the name the runtime gives a function.
+//
+// Parameters:
+//   - function: The function, of any function type.
+//
+// Returns:
+//   - name: The name, empty if the function is nil.
+func funcName(function any) (name string) {
+	value := reflect.ValueOf(function)
+
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return
+	}
+
+	if f := runtime.FuncForPC(value.Pointer()); f != nil {
+		name = f.Name()
+	}
+
+	return
+}
+
+// redactURL returns a URL with its password and query credentials redacted.
+//
+// Parameters:
+//   - raw: The URL.
+//
+// Returns:
+//   - redacted: The redacted URL, redactedValue if it cannot be parsed.
+func redactURL(raw string) (redacted string) {
+	if raw == "" {
+		return
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		redacted = redactedValue
+
+		return
+	}
+
+	redacted = redactedURL(parsed)
+
+	return
+}
+
+// redactProxyURL returns a proxy URL with its password redacted, parsing proxies without a
+// scheme (e.g., "user:password@proxy:8080") as HTTP proxies the way net/http does.
+//
+// Parameters:
+//   - proxy: The proxy URL.
+//
+// Returns:
+//   - redacted: The redacted URL.
+func redactProxyURL(proxy string) (redacted string) {
+	if proxy == "" {
+		return
+	}
+
+	parsed, err := url.Parse(proxy)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		proxy = "http://" + proxy
+	}
+
+	redacted = redactURL(proxy)
+
+	return
+}
@@ -0,0 +1,228 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// DefaultSoftNotFoundThreshold is the similarity to the calibration response from which a
+	// response is deemed a soft 404 when no threshold is given to NewSoftNotFoundDetector.
+	DefaultSoftNotFoundThreshold = 0.9
+
+	// softNotFoundBodyBytes is the number of body bytes compared.
+	softNotFoundBodyBytes = 64 << 10
+)
+
+// softNotFoundBaseline is the calibration response of a host: its answer to a path that does
+// not exist.
+type softNotFoundBaseline struct {
+	mutex      sync.Mutex
+	calibrated bool
+	status     int
+	words      map[string]struct{}
+}
+
+// SoftNotFoundDetector flags "soft 404" responses: responses to paths that do not exist which
+// servers send with a success status, such as a 200 OK error page, and which content discovery
+// would otherwise report as hits. Each host is calibrated once, with a request for a random
+// path, and responses with the same status as the calibration response and a body similar to
+// it are flagged. It is safe for concurrent use.
+type SoftNotFoundDetector struct {
+	client    *Client
+	threshold float64
+
+	mutex     sync.Mutex
+	baselines map[string]*softNotFoundBaseline
+}
+
+// SoftNotFound reports whether a response is a soft 404. Hosts answering paths that do not
+// exist with 404 Not Found or 410 Gone never send soft 404s. The body of the response is
+// left readable.
+//
+// Parameters:
+//   - ctx: The context of the calibration request, if the host is not calibrated yet.
+//   - res: The response.
+//
+// Returns:
+//   - softNotFound: Whether the response is a soft 404.
+//   - similarity: The similarity of the body to the calibration response, between 0 and 1.
+//   - err: The error of the calibration request, or of reading the body.
+func (d *SoftNotFoundDetector) SoftNotFound(ctx context.Context, res *http.Response) (softNotFound bool, similarity float64, err error) {
+	baseline, err := d.baseline(ctx, res.Request.URL)
+	if err != nil {
+		return
+	}
+
+	if baseline.status != res.StatusCode || baseline.status == http.StatusNotFound || baseline.status == http.StatusGone {
+		return
+	}
+
+	var body []byte
+
+	body, res.Body, err = readBodyPrefix(res.Body, softNotFoundBodyBytes)
+	if err != nil {
+		return
+	}
+
+	// Servers reflecting the requested path in error pages would otherwise differ by it.
+	similarity = jaccardSimilarity(baseline.words, softNotFoundWords(body, res.Request.URL.Path))
+
+	softNotFound = similarity >= d.threshold
+
+	return
+}
+
+// baseline returns the calibration response of the host of a URL, calibrating it first if
+// needed.
+//
+// Parameters:
+//   - ctx: The context of the calibration request.
+//   - target: The URL.
+//
+// Returns:
+//   - baseline: The calibration response.
+//   - err: The error of the calibration request.
+func (d *SoftNotFoundDetector) baseline(ctx context.Context, target *url.URL) (baseline *softNotFoundBaseline, err error) {
+	origin := strings.ToLower(target.Scheme + "://" + target.Host)
+
+	d.mutex.Lock()
+
+	baseline, ok := d.baselines[origin]
+	if !ok {
+		baseline = &softNotFoundBaseline{}
+
+		d.baselines[origin] = baseline
+	}
+
+	d.mutex.Unlock()
+
+	// Concurrent checks of an uncalibrated host wait for a single calibration, and failed ones
+	// are tried again by the next check.
+	baseline.mutex.Lock()
+
+	defer baseline.mutex.Unlock()
+
+	if baseline.calibrated {
+		return
+	}
+
+	random := "/" + randomHex(16)
+
+	res, err := d.client.GET(origin + random).Context(ctx).Send()
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, softNotFoundBodyBytes))
+	if err != nil {
+		return
+	}
+
+	baseline.status = res.StatusCode
+	baseline.words = softNotFoundWords(body, random)
+	baseline.calibrated = true
+
+	return
+}
+
+// softNotFoundWords returns the set of words of a body, leaving out the words of the requested
+// path, which error pages often reflect.
+//
+// Parameters:
+//   - body: The body.
+//   - requested: The requested path.
+//
+// Returns:
+//   - words: The words.
+func softNotFoundWords(body []byte, requested string) (words map[string]struct{}) {
+	words = make(map[string]struct{})
+
+	for _, word := range splitWords(string(body)) {
+		words[word] = struct{}{}
+	}
+
+	for _, word := range splitWords(requested) {
+		delete(words, word)
+	}
+
+	return
+}
+
+// splitWords splits a text into lowercased words, runs of letters and digits.
+//
+// Parameters:
+//   - text: The text.
+//
+// Returns:
+//   - words: The words, in order of appearance.
+func splitWords(text string) (words []string) {
+	words = strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return
+}
+
+// jaccardSimilarity returns the Jaccard index of two sets of words: the size of their
+// intersection over the size of their union, 1 for two empty sets.
+//
+// Parameters:
+//   - a: The first set.
+//   - b: The second set.
+//
+// Returns:
+//   - similarity: The similarity, between 0 and 1.
+func jaccardSimilarity(a, b map[string]struct{}) (similarity float64) {
+	if len(a) == 0 && len(b) == 0 {
+		similarity = 1
+
+		return
+	}
+
+	common := 0
+
+	for word := range a {
+		if _, ok := b[word]; ok {
+			common++
+		}
+	}
+
+	similarity = float64(common) / float64(len(a)+len(b)-common)
+
+	return
+}
+
+// NewSoftNotFoundDetector creates a SoftNotFoundDetector.
+//
+// Parameters:
+//   - client: The client sending calibration requests, DefaultClient if nil.
+//   - threshold: The similarity from which responses are deemed soft 404s,
+//     DefaultSoftNotFoundThreshold if not between 0 and 1.
+//
+// Returns:
+//   - detector: The new detector.
+func NewSoftNotFoundDetector(client *Client, threshold float64) (detector *SoftNotFoundDetector) {
+	if client == nil {
+		client = DefaultClient
+	}
+
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultSoftNotFoundThreshold
+	}
+
+	detector = &SoftNotFoundDetector{
+		client:    client,
+		threshold: threshold,
+		baselines: make(map[string]*softNotFoundBaseline),
+	}
+
+	return
+}
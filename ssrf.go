@@ -0,0 +1,273 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrForbiddenDestination is returned when a request would connect to an address refused by
+// the SSRF protection configured through ClientConfiguration.SSRFProtection, or targets a
+// scheme or port not listed in ClientConfiguration.AllowedSchemes or AllowedPorts.
+var ErrForbiddenDestination = errors.New("forbidden destination")
+
+// DefaultDeniedRanges are the address ranges refused by SSRFProtection when its Deny list is
+// nil: unspecified, loopback, private (RFC 1918, RFC 4193), shared (RFC 6598), link-local
+// (including the 169.254.169.254 cloud metadata endpoint), benchmarking, multicast and
+// reserved ranges.
+var DefaultDeniedRanges = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// SSRFProtection defines the addresses a client may connect to. Host names are resolved before
+// dialing and every resolved address is checked, so that names resolving to internal addresses
+// (including through DNS rebinding) are refused as well.
+//
+// When a proxy is used, the checked address is the proxy's, not the destination's.
+type SSRFProtection struct {
+	Deny  []netip.Prefix // Ranges refused, DefaultDeniedRanges if nil.
+	Allow []netip.Prefix // Ranges allowed even if they are part of a denied range.
+}
+
+// permits reports whether an address may be connected to.
+//
+// Parameters:
+//   - addr: The address to check.
+//
+// Returns:
+//   - permitted: Whether the address is permitted.
+func (p *SSRFProtection) permits(addr netip.Addr) (permitted bool) {
+	addr = addr.Unmap()
+
+	for _, prefix := range p.Allow {
+		if prefix.Contains(addr) {
+			permitted = true
+
+			return
+		}
+	}
+
+	deny := p.Deny
+
+	if deny == nil {
+		deny = DefaultDeniedRanges
+	}
+
+	for _, prefix := range deny {
+		if prefix.Contains(addr) {
+			return
+		}
+	}
+
+	permitted = true
+
+	return
+}
+
+// dialContext wraps a dial function so that it resolves the host itself, checks every
+// resolved address and only dials permitted ones, by address, so that the checked address is
+// the one connected to.
+//
+// Parameters:
+//   - dial: The dial function to wrap.
+//
+// Returns:
+//   - wrapped: The wrapping dial function.
+func (p *SSRFProtection) dialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) (wrapped func(ctx context.Context, network, address string) (net.Conn, error)) {
+	wrapped = func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		if isTrustedDestination(ctx) {
+			conn, err = dial(ctx, network, address)
+
+			return
+		}
+
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return
+		}
+
+		var addrs []netip.Addr
+
+		if addr, errr := netip.ParseAddr(host); errr == nil {
+			addrs = []netip.Addr{addr}
+		} else {
+			addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+			if err != nil {
+				return
+			}
+		}
+
+		err = fmt.Errorf("%w: %s", ErrForbiddenDestination, address)
+
+		for _, addr := range addrs {
+			if !p.permits(addr) {
+				continue
+			}
+
+			conn, err = dial(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+			if err == nil {
+				return
+			}
+		}
+
+		return
+	}
+
+	return
+}
+
+// TrustedDestination is a ContextOverride that, set to true on a request context, exempts the
+// request from SSRFProtection and from the AllowedSchemes and AllowedPorts checks, for
+// trusted callers sharing a client with untrusted input.
+const TrustedDestination ContextOverride = "trusted-destination"
+
+// PortRange is an inclusive range of TCP ports.
+type PortRange struct {
+	From uint16 // First port of the range.
+	To   uint16 // Last port of the range, From if lower than From.
+}
+
+// isTrustedDestination reports whether a context carries the TrustedDestination override.
+//
+// Parameters:
+//   - ctx: The request context.
+//
+// Returns:
+//   - trusted: Whether the request is exempted from destination checks.
+func isTrustedDestination(ctx context.Context) (trusted bool) {
+	trusted, _ = ctx.Value(TrustedDestination).(bool)
+
+	return
+}
+
+// checkDestination enforces ClientConfiguration.AllowedSchemes and AllowedPorts on a request,
+// before any network activity. Redirects are checked by checkRedirect.
+//
+// Parameters:
+//   - req: The request to check.
+//
+// Returns:
+//   - err: An error wrapping ErrForbiddenDestination if the scheme or port is not allowed.
+func (c *Client) checkDestination(req *Request) (err error) {
+	err = c.checkDestinationURL(req.Context(), req.URL)
+
+	return
+}
+
+// checkDestinationURL enforces ClientConfiguration.AllowedSchemes and AllowedPorts on a URL.
+//
+// Parameters:
+//   - ctx: The context of the request to the URL.
+//   - target: The URL to check.
+//
+// Returns:
+//   - err: An error wrapping ErrForbiddenDestination if the scheme or port is not allowed.
+func (c *Client) checkDestinationURL(ctx context.Context, target *url.URL) (err error) {
+	if len(c.cfg.AllowedSchemes) == 0 && len(c.cfg.AllowedPorts) == 0 || isTrustedDestination(ctx) {
+		return
+	}
+
+	scheme := strings.ToLower(target.Scheme)
+
+	if len(c.cfg.AllowedSchemes) > 0 && !slices.ContainsFunc(c.cfg.AllowedSchemes, func(allowed string) bool {
+		return strings.EqualFold(allowed, scheme)
+	}) {
+		err = fmt.Errorf("%w: scheme %q not allowed", ErrForbiddenDestination, scheme)
+
+		return
+	}
+
+	if len(c.cfg.AllowedPorts) == 0 {
+		return
+	}
+
+	rawPort := target.Port()
+
+	if rawPort == "" {
+		rawPort = defaultPorts[scheme]
+	}
+
+	port, errr := strconv.ParseUint(rawPort, 10, 16)
+	if errr != nil {
+		err = fmt.Errorf("%w: port %q not allowed", ErrForbiddenDestination, rawPort)
+
+		return
+	}
+
+	for _, allowed := range c.cfg.AllowedPorts {
+		if uint16(port) >= allowed.From && uint16(port) <= max(allowed.From, allowed.To) {
+			return
+		}
+	}
+
+	err = fmt.Errorf("%w: port %d not allowed", ErrForbiddenDestination, port)
+
+	return
+}
+
+// maxRedirects is the number of redirects net/http follows when an HTTP client has no
+// redirect policy.
+const maxRedirects = 10
+
+// configureRedirects makes an HTTP client check every redirect against
+// ClientConfiguration.AllowedSchemes and AllowedPorts, then against its own redirect policy,
+// so that an allowed server cannot redirect requests to a forbidden destination.
+//
+// Parameters:
+//   - httpClient: The HTTP client to configure.
+//
+// Returns: None.
+func (c *Client) configureRedirects(httpClient *http.Client) {
+	if len(c.cfg.AllowedSchemes) == 0 && len(c.cfg.AllowedPorts) == 0 {
+		return
+	}
+
+	policy := httpClient.CheckRedirect
+
+	httpClient.CheckRedirect = func(req *http.Request, via []*http.Request) (err error) {
+		if err = c.checkDestinationURL(req.Context(), req.URL); err != nil {
+			return
+		}
+
+		if policy != nil {
+			err = policy(req, via)
+
+			return
+		}
+
+		if len(via) >= maxRedirects {
+			err = fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+
+		return
+	}
+}
+
+// defaultPorts maps URL schemes to the port used when a URL does not specify one.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAllowedSchemesCheckRedirects(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer plain.Close()
+
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, plain.URL, http.StatusFound)
+	}))
+
+	defer secure.Close()
+
+	tests := []struct {
+		name    string
+		trusted bool
+		wantErr error
+	}{
+		{
+			name:    "redirect to forbidden scheme",
+			wantErr: ErrForbiddenDestination,
+		},
+		{
+			name:    "trusted destination",
+			trusted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(&ClientConfiguration{
+				Timeout:        5 * time.Second,
+				AllowedSchemes: []string{"https"},
+				TLS: TLSConfiguration{
+					InsecureSkipVerify: true,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.WithValue(context.Background(), TrustedDestination, tt.trusted)
+
+			res, err := client.GET(secure.URL).Context(ctx).Send()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			res.Body.Close()
+
+			if res.Request.URL.Scheme != "http" {
+				t.Fatalf("got final scheme %q, want %q", res.Request.URL.Scheme, "http")
+			}
+		})
+	}
+}
+
+func TestConfigureRedirectsKeepsPolicy(t *testing.T) {
+	errStop := errors.New("stop")
+
+	client := &Client{cfg: &ClientConfiguration{AllowedPorts: []PortRange{{From: 443}}}}
+
+	httpClient := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return errStop
+		},
+	}
+
+	client.configureRedirects(httpClient)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+
+	if err := httpClient.CheckRedirect(req, nil); !errors.Is(err, errStop) {
+		t.Fatalf("got error %v, want %v", err, errStop)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://example.com:8443/", nil)
+
+	if err := httpClient.CheckRedirect(req, nil); !errors.Is(err, ErrForbiddenDestination) {
+		t.Fatalf("got error %v, want %v", err, ErrForbiddenDestination)
+	}
+}
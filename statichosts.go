@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// ErrInvalidStaticHost is returned by NewClient when ClientConfiguration.StaticHosts maps a
+// host to something other than an IP address.
+var ErrInvalidStaticHost = errors.New("invalid static host")
+
+// staticHostsDialContext wraps a dial function to dial the IP addresses set through
+// ClientConfiguration.StaticHosts in place of the hosts they override, like entries of a hosts
+// file. Only the dialed address changes, so TLS still verifies the certificate for the host.
+//
+// Parameters:
+//   - hosts: The static hosts, keyed by lowercased hostname.
+//   - dial: The dial function to wrap.
+//
+// Returns:
+//   - wrapped: The wrapped dial function.
+func staticHostsDialContext(hosts map[string]string, dial func(ctx context.Context, network, address string) (net.Conn, error)) (wrapped func(ctx context.Context, network, address string) (net.Conn, error)) {
+	wrapped = func(ctx context.Context, network, address string) (net.Conn, error) {
+		if host, port, err := net.SplitHostPort(address); err == nil {
+			if IP, ok := hosts[strings.ToLower(host)]; ok {
+				address = net.JoinHostPort(IP, port)
+			}
+		}
+
+		return dial(ctx, network, address)
+	}
+
+	return
+}
+
+// checkStaticHosts checks that the static hosts map hosts to IP addresses.
+//
+// Parameters:
+//   - hosts: The static hosts.
+//
+// Returns:
+//   - err: An error wrapping ErrInvalidStaticHost for the first invalid entry.
+func checkStaticHosts(hosts map[string]string) (err error) {
+	for host, IP := range hosts {
+		if _, errr := netip.ParseAddr(IP); errr != nil {
+			err = fmt.Errorf("%w: %s: %q is not an IP address", ErrInvalidStaticHost, host, IP)
+
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,246 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math"
+	"net/http/httptrace"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Timing holds the timing breakdown of a single request attempt. Durations are measured
+// with the monotonic clock and are zero for phases that did not happen (e.g., DNS lookup
+// and connection establishment when a pooled connection was reused).
+type Timing struct {
+	DNSLookup        time.Duration // Time spent resolving the host.
+	Connect          time.Duration // Time spent establishing the TCP connection.
+	TLSHandshake     time.Duration // Time spent on the TLS handshake.
+	ServerProcessing time.Duration // Time between the request being fully written and the first response byte.
+	TimeToFirstByte  time.Duration // Time between the attempt starting and the first response byte.
+	ReusedConnection bool          // Whether the attempt was sent over a pooled connection.
+}
+
+// timingRecorder collects the httptrace events of a single attempt into a Timing.
+type timingRecorder struct {
+	mutex sync.Mutex
+
+	start, dnsStart, connectStart, tlsStart, wroteRequest time.Time
+
+	timing Timing
+}
+
+// trace returns the httptrace.ClientTrace feeding the recorder.
+//
+// Parameters: None.
+//
+// Returns:
+//   - trace: The client trace.
+func (r *timingRecorder) trace() (trace *httptrace.ClientTrace) {
+	trace = &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			r.mutex.Lock()
+			r.timing.ReusedConnection = info.Reused
+			r.mutex.Unlock()
+		},
+		DNSStart: func(_ httptrace.DNSStartInfo) {
+			r.mutex.Lock()
+			r.dnsStart = time.Now()
+			r.mutex.Unlock()
+		},
+		DNSDone: func(_ httptrace.DNSDoneInfo) {
+			r.mutex.Lock()
+			r.timing.DNSLookup = time.Since(r.dnsStart)
+			r.mutex.Unlock()
+		},
+		ConnectStart: func(_, _ string) {
+			r.mutex.Lock()
+			r.connectStart = time.Now()
+			r.mutex.Unlock()
+		},
+		ConnectDone: func(_, _ string, _ error) {
+			r.mutex.Lock()
+			r.timing.Connect = time.Since(r.connectStart)
+			r.mutex.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			r.mutex.Lock()
+			r.tlsStart = time.Now()
+			r.mutex.Unlock()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, _ error) {
+			r.mutex.Lock()
+			r.timing.TLSHandshake = time.Since(r.tlsStart)
+			r.mutex.Unlock()
+		},
+		WroteRequest: func(_ httptrace.WroteRequestInfo) {
+			r.mutex.Lock()
+			r.wroteRequest = time.Now()
+			r.mutex.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			r.mutex.Lock()
+			r.timing.TimeToFirstByte = time.Since(r.start)
+
+			if !r.wroteRequest.IsZero() {
+				r.timing.ServerProcessing = time.Since(r.wroteRequest)
+			}
+			r.mutex.Unlock()
+		},
+	}
+
+	return
+}
+
+// result returns the timing collected so far.
+//
+// Parameters: None.
+//
+// Returns:
+//   - timing: The collected timing.
+func (r *timingRecorder) result() (timing Timing) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	timing = r.timing
+
+	return
+}
+
+// newTimingRecorder creates a timingRecorder whose attempt starts now.
+//
+// Parameters: None.
+//
+// Returns:
+//   - recorder: The new recorder.
+func newTimingRecorder() (recorder *timingRecorder) {
+	recorder = &timingRecorder{
+		start: time.Now(),
+	}
+
+	return
+}
+
+// withTimingTrace returns a copy of ctx carrying a client trace that records into a new
+// timingRecorder, keeping any trace already attached to ctx.
+//
+// Parameters:
+//   - ctx: The attempt's context.
+//
+// Returns:
+//   - traced: The context carrying the trace.
+//   - recorder: The recorder the trace writes to.
+func withTimingTrace(ctx context.Context) (traced context.Context, recorder *timingRecorder) {
+	recorder = newTimingRecorder()
+
+	traced = httptrace.WithClientTrace(ctx, recorder.trace())
+
+	return
+}
+
+// TimingStatistics summarizes the server processing times observed over repeated requests.
+type TimingStatistics struct {
+	Samples []time.Duration // Server processing time of every repetition, in order.
+	Min     time.Duration   // Shortest observed server processing time.
+	Max     time.Duration   // Longest observed server processing time.
+	Mean    time.Duration   // Arithmetic mean of the server processing times.
+	Median  time.Duration   // Median of the server processing times.
+	StdDev  time.Duration   // Population standard deviation of the server processing times.
+}
+
+// ErrNoRepetitions is returned by Client.Measure when asked for less than one repetition.
+var ErrNoRepetitions = errors.New("at least one repetition is required")
+
+// Measure sends the request n times in sequence and returns statistics over the server
+// processing time (first response byte minus request written) of each repetition. Response
+// bodies are read fully and closed so every repetition measures the same work. It is meant
+// for timing based analysis, where the distribution matters more than any single sample.
+//
+// Parameters:
+//   - req: The request to repeat. Its body must be reusable, as with any request built by this package.
+//   - n: The number of repetitions.
+//
+// Returns:
+//   - statistics: The timing statistics.
+//   - err: The first error encountered, in which case the statistics are incomplete.
+func (c *Client) Measure(req *Request, n int) (statistics TimingStatistics, err error) {
+	if n < 1 {
+		err = ErrNoRepetitions
+
+		return
+	}
+
+	statistics.Samples = make([]time.Duration, 0, n)
+
+	for range n {
+		repetition := req.Clone(req.Context())
+
+		res, errr := c.Do(repetition)
+		if errr != nil {
+			err = errr
+
+			break
+		}
+
+		_, _ = io.Copy(io.Discard, res.Body)
+
+		res.Body.Close()
+
+		if timings := repetition.Metrics.Timings; len(timings) > 0 {
+			statistics.Samples = append(statistics.Samples, timings[len(timings)-1].ServerProcessing)
+		}
+	}
+
+	statistics.compute()
+
+	return
+}
+
+// compute fills the summary fields from the samples.
+//
+// Parameters: None.
+//
+// Returns: None.
+func (s *TimingStatistics) compute() {
+	if len(s.Samples) == 0 {
+		return
+	}
+
+	sorted := slices.Clone(s.Samples)
+
+	slices.Sort(sorted)
+
+	s.Min = sorted[0]
+	s.Max = sorted[len(sorted)-1]
+
+	middle := len(sorted) / 2
+
+	s.Median = sorted[middle]
+
+	if len(sorted)%2 == 0 {
+		s.Median = (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	var sum float64
+
+	for _, sample := range sorted {
+		sum += float64(sample)
+	}
+
+	mean := sum / float64(len(sorted))
+
+	var variance float64
+
+	for _, sample := range sorted {
+		variance += (float64(sample) - mean) * (float64(sample) - mean)
+	}
+
+	variance /= float64(len(sorted))
+
+	s.Mean = time.Duration(mean)
+	s.StdDev = time.Duration(math.Sqrt(variance))
+}
@@ -0,0 +1,641 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TLSConfiguration defines the TLS settings applied to the transports built by NewClient.
+type TLSConfiguration struct {
+	// CABundlePaths lists PEM files, or directories whose files are all read, holding
+	// certificate authorities trusted in addition to the system roots (e.g., the CA of a
+	// corporate TLS interception proxy).
+	CABundlePaths []string
+	// CABundleReloadInterval is how often the CA bundle paths are checked for changes.
+	// Changed bundles are reloaded without recreating the client. Zero disables reloading.
+	CABundleReloadInterval time.Duration
+	// GetClientCertificate, if set, is called to pick the client certificate on every TLS
+	// handshake asking for one, so that short-lived certificates can be rotated without
+	// recreating the client. See ClientCertificateReloader for a file based implementation.
+	GetClientCertificate func(info *tls.CertificateRequestInfo) (certificate *tls.Certificate, err error)
+	// ClientCertificates are presented to servers asking for a client certificate, the first
+	// one they accept being picked. Ignored if GetClientCertificate is set.
+	ClientCertificates []tls.Certificate
+	// RootCAs, if set, replaces the system roots as the certificate authorities trusted,
+	// CABundlePaths adding to them.
+	RootCAs *x509.CertPool
+	// ServerName, if set, is sent in SNI and verified against the certificates of every
+	// server in place of the host name, e.g. to reach a virtual host through an IP address.
+	ServerName string
+	// MinVersion and MaxVersion bound the TLS versions negotiated (e.g., tls.VersionTLS13).
+	// Zero keeps the bounds of the transport, TLS 1.2 being the minimum of transports without
+	// a TLS configuration.
+	MinVersion uint16
+	MaxVersion uint16
+	// CipherSuites lists the TLS 1.2 and earlier cipher suites enabled, TLS 1.3 suites not
+	// being configurable. Empty keeps the defaults of crypto/tls.
+	CipherSuites []uint16
+	// InsecureSkipVerify disables the verification of server certificates, CA bundles
+	// included, e.g. to scan targets with self-signed certificates. See
+	// RequestBuilder.InsecureSkipVerify to disable it for a single request.
+	InsecureSkipVerify bool
+}
+
+// overridesTransport reports whether the configuration changes the TLS client configuration
+// of transports.
+//
+// Parameters: None.
+//
+// Returns:
+//   - overrides: Whether any setting is set.
+func (t *TLSConfiguration) overridesTransport() (overrides bool) {
+	overrides = len(t.CABundlePaths) > 0 || t.GetClientCertificate != nil || len(t.ClientCertificates) > 0 ||
+		t.RootCAs != nil || t.ServerName != "" || t.MinVersion != 0 || t.MaxVersion != 0 || len(t.CipherSuites) > 0 ||
+		t.InsecureSkipVerify
+
+	return
+}
+
+// CABundleStatistics describes the loading history of the CA bundles of a client.
+type CABundleStatistics struct {
+	Reloads    uint64    // Number of successful (re)loads, including the initial one.
+	Failures   uint64    // Number of files that could not be read or held no certificate.
+	LastReload time.Time // Time of the last successful (re)load.
+	LastError  error     // Last read or parse error, nil if none occurred.
+}
+
+// ErrNoCACertificates is returned when the configured CA bundle paths hold no certificate.
+var ErrNoCACertificates = errors.New("no CA certificates found in CA bundle paths")
+
+// caBundle holds the certificate pool built from the configured CA bundle paths and
+// reloads it when the files change.
+type caBundle struct {
+	roots    *x509.CertPool
+	paths    []string
+	interval time.Duration
+
+	mutex       sync.RWMutex
+	pool        *x509.CertPool
+	signature   string
+	lastChecked time.Time
+	statistics  CABundleStatistics
+}
+
+// currentPool returns the certificate pool, reloading it first if the reload interval has
+// elapsed and the bundle files changed since the last load.
+//
+// Parameters: None.
+//
+// Returns:
+//   - pool: The certificate pool to verify peers against.
+func (b *caBundle) currentPool() (pool *x509.CertPool) {
+	b.mutex.RLock()
+
+	pool = b.pool
+	due := b.interval > 0 && time.Since(b.lastChecked) >= b.interval
+
+	b.mutex.RUnlock()
+
+	if !due {
+		return
+	}
+
+	b.mutex.Lock()
+
+	defer b.mutex.Unlock()
+
+	b.lastChecked = time.Now()
+
+	if signature := b.files().signature(); signature == b.signature {
+		return
+	}
+
+	if err := b.load(); err != nil {
+		b.statistics.LastError = err
+	}
+
+	pool = b.pool
+
+	return
+}
+
+// load (re)builds the certificate pool from the bundle paths. The caller must hold the
+// write lock. The previous pool is kept if no certificate could be loaded.
+//
+// Parameters: None.
+//
+// Returns:
+//   - err: ErrNoCACertificates if no certificate could be loaded.
+func (b *caBundle) load() (err error) {
+	var pool *x509.CertPool
+
+	if b.roots != nil {
+		pool = b.roots.Clone()
+	} else if pool, err = x509.SystemCertPool(); err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	files := b.files()
+
+	loaded := 0
+
+	for _, file := range files {
+		content, errr := os.ReadFile(file.path)
+		if errr != nil {
+			b.statistics.Failures++
+			b.statistics.LastError = errr
+
+			continue
+		}
+
+		if !pool.AppendCertsFromPEM(content) {
+			b.statistics.Failures++
+			b.statistics.LastError = fmt.Errorf("%w: %s", ErrNoCACertificates, file.path)
+
+			continue
+		}
+
+		loaded++
+	}
+
+	b.signature = files.signature()
+
+	if loaded == 0 {
+		err = ErrNoCACertificates
+
+		return
+	}
+
+	b.pool = pool
+
+	b.statistics.Reloads++
+	b.statistics.LastReload = time.Now()
+
+	return
+}
+
+// watchedFile is a file reloaded on change, along with the state used to detect changes.
+type watchedFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// watchedFiles is a list of files reloaded on change.
+type watchedFiles []watchedFile
+
+// signature summarizes the files so that any change (added, removed, resized or modified
+// file) yields a different signature.
+//
+// Parameters: None.
+//
+// Returns:
+//   - signature: The signature.
+func (f watchedFiles) signature() (signature string) {
+	for _, file := range f {
+		signature += fmt.Sprintf("%s|%d|%d\n", file.path, file.size, file.modTime.UnixNano())
+	}
+
+	return
+}
+
+// files lists the files of the bundle paths, expanding directories one level deep.
+//
+// Parameters: None.
+//
+// Returns:
+//   - files: The bundle files, in path order.
+func (b *caBundle) files() (files watchedFiles) {
+	for _, path := range b.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		if !info.IsDir() {
+			files = append(files, watchedFile{path: path, size: info.Size(), modTime: info.ModTime()})
+
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			entryInfo, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			files = append(files, watchedFile{
+				path:    filepath.Join(path, entry.Name()),
+				size:    entryInfo.Size(),
+				modTime: entryInfo.ModTime(),
+			})
+		}
+	}
+
+	return
+}
+
+// verifyConnection verifies the peer certificate chain against the current pool, in place
+// of the verification crypto/tls would do against a pool fixed at configuration time. It is
+// used for connections the transport establishes itself (e.g., TLS through a proxy tunnel),
+// whose state only names the host if it is not an IP address: those to IP addresses are
+// refused rather than verified without their name.
+//
+// Parameters:
+//   - state: The state of the TLS connection being established.
+//
+// Returns:
+//   - err: An error if the chain does not verify.
+func (b *caBundle) verifyConnection(state tls.ConnectionState) (err error) {
+	if state.ServerName == "" {
+		err = errUnverifiableServerName
+
+		return
+	}
+
+	err = b.verify(state, state.ServerName)
+
+	return
+}
+
+// verify verifies the peer certificate chain against the current pool and a server name.
+//
+// Parameters:
+//   - state: The state of the TLS connection being established.
+//   - name: The host name or IP address the certificate must be valid for.
+//
+// Returns:
+//   - err: An error if the chain does not verify.
+func (b *caBundle) verify(state tls.ConnectionState, name string) (err error) {
+	if len(state.PeerCertificates) == 0 {
+		err = errNoPeerCertificates
+
+		return
+	}
+
+	options := x509.VerifyOptions{
+		DNSName:       name,
+		Roots:         b.currentPool(),
+		Intermediates: x509.NewCertPool(),
+	}
+
+	for _, certificate := range state.PeerCertificates[1:] {
+		options.Intermediates.AddCert(certificate)
+	}
+
+	_, err = state.PeerCertificates[0].Verify(options)
+
+	return
+}
+
+// dialTLSContext returns the TLS dial function of a transport verifying servers against the
+// bundle, which establishes the TLS connections the transport dials directly (to servers and
+// HTTPS proxies). Connections are verified against the dialed host, closed over per dial, so
+// that the certificates of IP addresses are checked against their IP SANs.
+//
+// Parameters:
+//   - transport: The transport, whose dial function and TLS configuration are read per dial.
+//
+// Returns:
+//   - dial: The TLS dial function.
+func (b *caBundle) dialTLSContext(transport *http.Transport) (dial func(ctx context.Context, network, address string) (net.Conn, error)) {
+	dial = func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return
+		}
+
+		dialContext := transport.DialContext
+
+		if dialContext == nil {
+			dialContext = (&net.Dialer{}).DialContext
+		}
+
+		raw, err := dialContext(ctx, network, address)
+		if err != nil {
+			return
+		}
+
+		config := transport.TLSClientConfig.Clone()
+
+		if config.ServerName == "" {
+			config.ServerName = host
+		}
+
+		// A configuration without VerifyConnection skips verification on purpose (see
+		// InsecureSkipVerify) or verifies with crypto/tls itself (see TLSConfig).
+		if config.VerifyConnection != nil {
+			name := config.ServerName
+
+			config.VerifyConnection = func(state tls.ConnectionState) error {
+				return b.verify(state, name)
+			}
+		}
+
+		if transport.TLSHandshakeTimeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, transport.TLSHandshakeTimeout)
+
+			defer cancel()
+		}
+
+		trace := httptrace.ContextClientTrace(ctx)
+
+		if trace != nil && trace.TLSHandshakeStart != nil {
+			trace.TLSHandshakeStart()
+		}
+
+		tlsConn := tls.Client(raw, config)
+
+		err = tlsConn.HandshakeContext(ctx)
+
+		if trace != nil && trace.TLSHandshakeDone != nil {
+			trace.TLSHandshakeDone(tlsConn.ConnectionState(), err)
+		}
+
+		if err != nil {
+			raw.Close()
+
+			return
+		}
+
+		conn = tlsConn
+
+		return
+	}
+
+	return
+}
+
+var (
+	// errNoPeerCertificates is returned when a server presents no certificate.
+	errNoPeerCertificates = errors.New("tls: server presented no certificates")
+	// errUnverifiableServerName is returned when the name a server must be verified against is
+	// unknown, as for IP addresses reached through proxy tunnels.
+	errUnverifiableServerName = errors.New("tls: cannot verify server name against CA bundle")
+)
+
+// statisticsSnapshot returns a copy of the loading statistics.
+//
+// Parameters: None.
+//
+// Returns:
+//   - statistics: The statistics.
+func (b *caBundle) statisticsSnapshot() (statistics CABundleStatistics) {
+	b.mutex.RLock()
+
+	defer b.mutex.RUnlock()
+
+	statistics = b.statistics
+
+	return
+}
+
+// newCABundle creates a caBundle and performs the initial load.
+//
+// Parameters:
+//   - roots: The certificate authorities the bundles add to, nil for the system roots.
+//   - paths: The CA bundle files or directories.
+//   - interval: The reload check interval, zero to disable reloading.
+//
+// Returns:
+//   - bundle: The loaded bundle.
+//   - err: ErrNoCACertificates if no certificate could be loaded.
+func newCABundle(roots *x509.CertPool, paths []string, interval time.Duration) (bundle *caBundle, err error) {
+	bundle = &caBundle{
+		roots:       roots,
+		paths:       paths,
+		interval:    interval,
+		lastChecked: time.Now(),
+	}
+
+	bundle.mutex.Lock()
+
+	defer bundle.mutex.Unlock()
+
+	err = bundle.load()
+
+	return
+}
+
+// configureTLS applies the client's TLS configuration to a transport, keeping the parts of
+// its existing TLS client configuration (e.g., the ALPN protocols set up for HTTP/2).
+//
+// Parameters:
+//   - transport: The transport to configure.
+//
+// Returns: None.
+func (c *Client) configureTLS(transport *http.Transport) {
+	if !c.cfg.TLS.overridesTransport() {
+		return
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	config := transport.TLSClientConfig
+
+	if c.cfg.TLS.MinVersion != 0 {
+		config.MinVersion = c.cfg.TLS.MinVersion
+	}
+
+	if c.cfg.TLS.MaxVersion != 0 {
+		config.MaxVersion = c.cfg.TLS.MaxVersion
+	}
+
+	if len(c.cfg.TLS.CipherSuites) > 0 {
+		config.CipherSuites = c.cfg.TLS.CipherSuites
+	}
+
+	if c.cfg.TLS.RootCAs != nil {
+		config.RootCAs = c.cfg.TLS.RootCAs
+	}
+
+	if len(c.cfg.TLS.ClientCertificates) > 0 {
+		config.Certificates = c.cfg.TLS.ClientCertificates
+	}
+
+	if c.cfg.TLS.ServerName != "" {
+		config.ServerName = c.cfg.TLS.ServerName
+	}
+
+	switch {
+	case c.cfg.TLS.InsecureSkipVerify:
+		config.InsecureSkipVerify = true //nolint:gosec // Explicitly requested.
+	case c.caBundle != nil:
+		// Verification is done by VerifyConnection against a pool that can be swapped at any time,
+		// which crypto/tls does not support for its built-in verification.
+		config.InsecureSkipVerify = true //nolint:gosec // Verified in VerifyConnection.
+		config.VerifyConnection = c.caBundle.verifyConnection
+
+		if transport.DialTLSContext == nil && transport.DialTLS == nil { //nolint:staticcheck // DialTLSContext would silently replace DialTLS.
+			transport.DialTLSContext = c.caBundle.dialTLSContext(transport)
+		}
+	}
+
+	if c.cfg.TLS.GetClientCertificate != nil {
+		config.GetClientCertificate = c.cfg.TLS.GetClientCertificate
+	}
+}
+
+// CABundleStatistics returns the loading history of the CA bundles configured through
+// ClientConfiguration.TLS.CABundlePaths, e.g. to export reload and failure counts.
+//
+// Parameters: None.
+//
+// Returns:
+//   - statistics: The statistics, zero if no CA bundle is configured.
+func (c *Client) CABundleStatistics() (statistics CABundleStatistics) {
+	if c.caBundle == nil {
+		return
+	}
+
+	statistics = c.caBundle.statisticsSnapshot()
+
+	return
+}
+
+// ClientCertificateReloader serves a client certificate and key loaded from PEM files and
+// reloads them when the files change, for short-lived certificates issued by e.g. SPIFFE or
+// Vault agents. Its GetClientCertificate method is meant for TLSConfiguration.GetClientCertificate.
+// Established connections keep the certificate they were set up with; new connections pick
+// up the rotated one, so the connection pool is not dropped.
+type ClientCertificateReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mutex       sync.RWMutex
+	certificate *tls.Certificate
+	signature   string
+	lastChecked time.Time
+}
+
+// GetClientCertificate returns the current client certificate, reloading it first if the
+// reload interval has elapsed and the files changed. If reloading fails, for example while
+// the files are being rewritten, the previous certificate keeps being served.
+//
+// Parameters:
+//   - info: The certificate request of the server. Unused.
+//
+// Returns:
+//   - certificate: The client certificate.
+//   - err: Always nil.
+func (r *ClientCertificateReloader) GetClientCertificate(_ *tls.CertificateRequestInfo) (certificate *tls.Certificate, err error) {
+	r.mutex.RLock()
+
+	certificate = r.certificate
+	due := r.interval > 0 && time.Since(r.lastChecked) >= r.interval
+
+	r.mutex.RUnlock()
+
+	if !due {
+		return
+	}
+
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.lastChecked = time.Now()
+
+	if r.files().signature() != r.signature {
+		_ = r.load()
+	}
+
+	certificate = r.certificate
+
+	return
+}
+
+// load reads the certificate and key files. The caller must hold the write lock. The
+// previous certificate is kept if the files cannot be loaded.
+//
+// Parameters: None.
+//
+// Returns:
+//   - err: An error if the files cannot be read or do not hold a matching pair.
+func (r *ClientCertificateReloader) load() (err error) {
+	signature := r.files().signature()
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		err = fmt.Errorf("loading client certificate: %w", err)
+
+		return
+	}
+
+	r.certificate = &certificate
+	r.signature = signature
+
+	return
+}
+
+// files returns the certificate and key files along with the state used to detect changes.
+//
+// Parameters: None.
+//
+// Returns:
+//   - files: The certificate and key files, omitting the ones that cannot be stat'ed.
+func (r *ClientCertificateReloader) files() (files watchedFiles) {
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		files = append(files, watchedFile{path: path, size: info.Size(), modTime: info.ModTime()})
+	}
+
+	return
+}
+
+// NewClientCertificateReloader creates a ClientCertificateReloader and loads the certificate.
+//
+// Parameters:
+//   - certFile: The path of the PEM encoded certificate (chain).
+//   - keyFile: The path of the PEM encoded private key.
+//   - interval: How often the files are checked for changes. Zero disables reloading.
+//
+// Returns:
+//   - reloader: The reloader.
+//   - err: An error if the initial certificate cannot be loaded.
+func NewClientCertificateReloader(certFile, keyFile string, interval time.Duration) (reloader *ClientCertificateReloader, err error) {
+	reloader = &ClientCertificateReloader{
+		certFile:    certFile,
+		keyFile:     keyFile,
+		interval:    interval,
+		lastChecked: time.Now(),
+	}
+
+	reloader.mutex.Lock()
+
+	defer reloader.mutex.Unlock()
+
+	err = reloader.load()
+
+	return
+}
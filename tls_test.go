@@ -0,0 +1,247 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testCA is a certificate authority issuing server certificates for tests.
+type testCA struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pem         []byte
+}
+
+func newTestCA(t *testing.T) (ca *testCA) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ca = &testCA{
+		certificate: certificate,
+		key:         key,
+		pem:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+
+	return
+}
+
+func (ca *testCA) issue(t *testing.T, dnsNames []string, ips []net.IP) (certificate tls.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certificate = tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+
+	return
+}
+
+func TestCABundleVerifiesServerNames(t *testing.T) {
+	ca := newTestCA(t)
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+
+	if err := os.WriteFile(bundle, ca.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		dnsNames    []string
+		ips         []net.IP
+		staticHosts map[string]string
+		host        string
+		wantErr     bool
+	}{
+		{
+			name: "IP address in IP SANs",
+			ips:  []net.IP{net.IPv4(127, 0, 0, 1)},
+			host: "127.0.0.1",
+		},
+		{
+			name:     "IP address missing from SANs",
+			dnsNames: []string{"example.com"},
+			host:     "127.0.0.1",
+			wantErr:  true,
+		},
+		{
+			name:     "IP address in DNS SANs only",
+			dnsNames: []string{"127.0.0.1"},
+			host:     "127.0.0.1",
+			wantErr:  true,
+		},
+		{
+			name:        "host name in DNS SANs",
+			dnsNames:    []string{"example.com"},
+			staticHosts: map[string]string{"example.com": "127.0.0.1"},
+			host:        "example.com",
+		},
+		{
+			name:        "host name missing from SANs",
+			dnsNames:    []string{"example.org"},
+			staticHosts: map[string]string{"example.com": "127.0.0.1"},
+			host:        "example.com",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+
+			// Refused handshakes are expected.
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+
+			server.TLS = &tls.Config{
+				Certificates: []tls.Certificate{ca.issue(t, tt.dnsNames, tt.ips)},
+				MinVersion:   tls.VersionTLS12,
+			}
+
+			server.StartTLS()
+
+			defer server.Close()
+
+			client, err := NewClient(&ClientConfiguration{
+				Timeout:     5 * time.Second,
+				StaticHosts: tt.staticHosts,
+				TLS: TLSConfiguration{
+					CABundlePaths: []string{bundle},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+			res, err := client.GET("https://" + net.JoinHostPort(tt.host, port) + "/").Send()
+			if tt.wantErr {
+				if err == nil {
+					res.Body.Close()
+
+					t.Fatal("expected a verification error, got none")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			res.Body.Close()
+
+			if res.StatusCode != http.StatusNoContent {
+				t.Fatalf("got status %d, want %d", res.StatusCode, http.StatusNoContent)
+			}
+		})
+	}
+}
+
+func TestIsolatedTransportsBounded(t *testing.T) {
+	var opened, closed atomic.Int32
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			opened.Add(1)
+		case http.StateClosed, http.StateHijacked:
+			closed.Add(1)
+		}
+	}
+
+	server.StartTLS()
+
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfiguration{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every request carries a configuration of its own, as a caller rebuilding it would.
+	for range maxIsolatedTransports + 8 {
+		res, err := client.GET(server.URL).TLSConfig(&tls.Config{InsecureSkipVerify: true}).Send() //nolint:gosec // Test server.
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res.Body.Close()
+	}
+
+	if cached := len(client.isolatedTransports.transports); cached != maxIsolatedTransports {
+		t.Fatalf("got %d cached transports, want %d", cached, maxIsolatedTransports)
+	}
+
+	client.CloseIdleConnections()
+
+	for deadline := time.Now().Add(5 * time.Second); closed.Load() < opened.Load(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of %d connections closed", closed.Load(), opened.Load())
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sync"
+
+	"golang.org/x/net/http2"
+)
+
+const (
+	// ClientCertificate overrides the client certificates of ClientConfiguration.TLS for a
+	// request when set to a tls.Certificate on its context (see RequestBuilder.ClientCertificate).
+	ClientCertificate ContextOverride = "client-certificate"
+	// TLSConfig replaces the TLS client configuration of the transport for a request when set
+	// to a *tls.Config on its context (see RequestBuilder.TLSConfig). Transports are isolated
+	// per *tls.Config pointer: requests sharing one share connections. Only the transports of
+	// the latest overrides are kept, so configurations should be shared rather than rebuilt
+	// for every request.
+	TLSConfig ContextOverride = "tls-config"
+	// InsecureSkipVerify disables the verification of server certificates for a request when
+	// set to true on its context (see RequestBuilder.InsecureSkipVerify).
+	InsecureSkipVerify ContextOverride = "insecure-skip-verify"
+)
+
+// maxIsolatedTransports is the number of isolated transports a client keeps, the least recently
+// used ones being evicted, e.g. when every request carries a *tls.Config of its own.
+const maxIsolatedTransports = 32
+
+// ErrTLSOverride is returned for requests overriding their TLS configuration sent through a
+// transport other than *http.Transport, which cannot be isolated.
+var ErrTLSOverride = errors.New("TLS override needs an *http.Transport")
+
+// isolatedTransportKey identifies the isolated transport of a TLS override of a transport.
+type isolatedTransportKey struct {
+	base        *http.Transport
+	config      *tls.Config
+	certificate string
+	insecure    bool
+}
+
+// isolatedTransportCache keeps the isolated transports of TLS overrides, up to
+// maxIsolatedTransports. The zero value is an empty cache.
+type isolatedTransportCache struct {
+	mutex      sync.Mutex
+	transports map[isolatedTransportKey]*http.Transport
+	recent     []isolatedTransportKey
+}
+
+// get returns the isolated transport of an override, creating it if it is not cached. The
+// idle connections of the transports evicted to make room are closed.
+//
+// Parameters:
+//   - key: The key of the override.
+//   - create: The function creating the transport.
+//
+// Returns:
+//   - transport: The isolated transport.
+//   - err: The error of create.
+func (c *isolatedTransportCache) get(key isolatedTransportKey, create func() (*http.Transport, error)) (transport *http.Transport, err error) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	transport, ok := c.transports[key]
+	if !ok {
+		transport, err = create()
+		if err != nil {
+			return
+		}
+
+		if c.transports == nil {
+			c.transports = make(map[isolatedTransportKey]*http.Transport)
+		}
+
+		c.transports[key] = transport
+	}
+
+	c.recent = slices.DeleteFunc(c.recent, func(recent isolatedTransportKey) bool { return recent == key })
+	c.recent = append(c.recent, key)
+
+	for len(c.recent) > maxIsolatedTransports {
+		c.transports[c.recent[0]].CloseIdleConnections()
+
+		delete(c.transports, c.recent[0])
+
+		c.recent = c.recent[1:]
+	}
+
+	return
+}
+
+// closeIdleConnections closes the idle connections of every cached transport.
+//
+// Parameters: None.
+//
+// Returns: None.
+func (c *isolatedTransportCache) closeIdleConnections() {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	for _, transport := range c.transports {
+		transport.CloseIdleConnections()
+	}
+}
+
+// tlsOverride holds the TLS overrides of a request.
+type tlsOverride struct {
+	config         *tls.Config
+	certificate    tls.Certificate
+	hasCertificate bool
+	insecure       bool
+}
+
+// isolateTLS returns the HTTP client to send a request overriding its TLS configuration (see
+// ClientCertificate, TLSConfig and InsecureSkipVerify) through: a copy of the given client using a transport of
+// its own, so that connections established with one identity are never reused for another.
+// Isolated transports are cloned from the client's, dialing the same way, and kept per
+// override to reuse their connections, up to maxIsolatedTransports.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - httpClient: The HTTP client the request would be sent through.
+//
+// Returns:
+//   - isolated: The HTTP client to send the request through, httpClient if it has no override.
+//   - err: An error wrapping ErrTLSOverride if the transport cannot be isolated.
+func (c *Client) isolateTLS(ctx context.Context, httpClient *http.Client) (isolated *http.Client, err error) {
+	isolated = httpClient
+
+	var override tlsOverride
+
+	override.config, _ = ctx.Value(TLSConfig).(*tls.Config)
+	override.certificate, override.hasCertificate = ctx.Value(ClientCertificate).(tls.Certificate)
+	override.insecure, _ = ctx.Value(InsecureSkipVerify).(bool)
+
+	if override.config == nil && !override.hasCertificate && !override.insecure {
+		return
+	}
+
+	base, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		err = fmt.Errorf("%w: got %T", ErrTLSOverride, httpClient.Transport)
+
+		return
+	}
+
+	key := isolatedTransportKey{
+		base:     base,
+		config:   override.config,
+		insecure: override.insecure,
+	}
+
+	// Certificates are told apart by their leaf, which the same certificate loaded twice shares.
+	if override.hasCertificate && len(override.certificate.Certificate) > 0 {
+		key.certificate = string(override.certificate.Certificate[0])
+	}
+
+	transport, err := c.isolatedTransports.get(key, func() (*http.Transport, error) {
+		return isolatedTransport(base, override, c.caBundle)
+	})
+	if err != nil {
+		return
+	}
+
+	copied := *httpClient
+
+	copied.Transport = transport
+
+	isolated = &copied
+
+	return
+}
+
+// isolatedTransport clones a transport for a TLS override.
+//
+// Parameters:
+//   - base: The transport to clone.
+//   - override: The TLS overrides.
+//   - bundle: The CA bundle the transport verifies servers against, nil if none.
+//
+// Returns:
+//   - transport: The new transport.
+//   - err: An error if HTTP/2 cannot be configured on the new transport.
+func isolatedTransport(base *http.Transport, override tlsOverride, bundle *caBundle) (transport *http.Transport, err error) {
+	transport = base.Clone()
+
+	switch {
+	case override.config != nil:
+		transport.TLSClientConfig = override.config.Clone()
+	case transport.TLSClientConfig == nil:
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	if override.hasCertificate {
+		transport.TLSClientConfig.Certificates = []tls.Certificate{override.certificate}
+		transport.TLSClientConfig.GetClientCertificate = nil
+	}
+
+	// The verification of CA bundles, done by VerifyConnection, is disabled as well.
+	if override.insecure {
+		transport.TLSClientConfig.InsecureSkipVerify = true //nolint:gosec // Explicitly requested.
+		transport.TLSClientConfig.VerifyConnection = nil
+	}
+
+	// The TLS dial function of the CA bundle reads the TLS configuration of the transport it
+	// was created for, which is the base one. Functions cannot be compared, but their code
+	// pointers can.
+	if bundle != nil && transport.DialTLSContext != nil &&
+		reflect.ValueOf(transport.DialTLSContext).Pointer() == reflect.ValueOf(bundle.dialTLSContext(base)).Pointer() {
+		transport.DialTLSContext = bundle.dialTLSContext(transport)
+	}
+
+	// The HTTP/2 support of a transport configured by http2.ConfigureTransport is cloned along
+	// with its connection pool, which would mix identities: it is configured afresh.
+	if _, ok := transport.TLSNextProto["h2"]; ok {
+		transport.TLSNextProto = nil
+
+		err = http2.ConfigureTransport(transport)
+	}
+
+	return
+}
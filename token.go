@@ -0,0 +1,170 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// TokenProvider provides the access tokens sent, set through ClientConfiguration.TokenProvider,
+// in the Authorization header of every attempt, so that long running retries do not send
+// stale tokens. Implementations must be safe for concurrent use.
+type TokenProvider interface {
+	// Token returns the access token to send.
+	Token(ctx context.Context) (token string, err error)
+}
+
+// TokenInvalidator is implemented by TokenProviders caching tokens. When an attempt is
+// rejected with 401 Unauthorized, the token it carried is invalidated and the request is sent
+// again once with a fresh token.
+type TokenInvalidator interface {
+	// Invalidate discards a token rejected by a server, if it is still the current one.
+	Invalidate(token string)
+}
+
+// TokenFetcher defines a function type fetching a new access token, e.g. from an OAuth 2.0
+// token endpoint.
+//
+// Parameters:
+//   - ctx: The context of the attempt the token is fetched for.
+//
+// Returns:
+//   - token: The access token.
+//   - expiry: When the token expires, zero if it does not.
+//   - err: An error if no token can be fetched.
+type TokenFetcher func(ctx context.Context) (token string, expiry time.Time, err error)
+
+// RefreshingTokenProvider is a TokenProvider caching the token of a TokenFetcher until it
+// expires, or is invalidated after a 401 Unauthorized response. Concurrent attempts needing
+// a new token wait for a single fetch.
+type RefreshingTokenProvider struct {
+	fetch  TokenFetcher
+	margin time.Duration
+
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// Token returns the cached token, fetching a new one if there is none or it expires within
+// the refresh margin.
+//
+// Parameters:
+//   - ctx: The context of the attempt.
+//
+// Returns:
+//   - token: The access token.
+//   - err: The error of the fetcher.
+func (p *RefreshingTokenProvider) Token(ctx context.Context) (token string, err error) {
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	if p.token != "" && (p.expiry.IsZero() || time.Until(p.expiry) > p.margin) {
+		token = p.token
+
+		return
+	}
+
+	token, expiry, err := p.fetch(ctx)
+	if err != nil {
+		return
+	}
+
+	p.token, p.expiry = token, expiry
+
+	return
+}
+
+// Invalidate discards a token if it is still the cached one, so that the next call to Token
+// fetches a new one.
+//
+// Parameters:
+//   - token: The rejected token.
+//
+// Returns: None.
+func (p *RefreshingTokenProvider) Invalidate(token string) {
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	if p.token == token {
+		p.token = ""
+	}
+}
+
+// applyToken sets the Authorization header of an attempt to the token of the configured
+// TokenProvider, using the DPoP scheme if ClientConfiguration.DPoP is set and the Bearer
+// scheme otherwise. Requests carrying an Authorization header are left as is.
+//
+// Parameters:
+//   - attempt: The request of the attempt.
+//
+// Returns:
+//   - token: The token set, empty if none.
+//   - err: The error of the token provider.
+func (c *Client) applyToken(attempt *http.Request) (token string, err error) {
+	if c.cfg.TokenProvider == nil || attempt.Header.Get(headers.Authorization.String()) != "" {
+		return
+	}
+
+	token, err = c.cfg.TokenProvider.Token(attempt.Context())
+	if err != nil {
+		return
+	}
+
+	value := headers.BuildBearer(token)
+
+	if c.cfg.DPoP != nil {
+		value = "DPoP " + token
+	}
+
+	attempt.Header.Set(headers.Authorization.String(), value)
+
+	return
+}
+
+// invalidateToken invalidates the token of an attempt rejected with 401 Unauthorized.
+//
+// Parameters:
+//   - token: The token the attempt was sent with, empty if none.
+//   - res: The response of the attempt.
+//
+// Returns:
+//   - invalidated: Whether the token was invalidated, and the request is to be sent again.
+func (c *Client) invalidateToken(token string, res *http.Response) (invalidated bool) {
+	if token == "" || res.StatusCode != http.StatusUnauthorized {
+		return
+	}
+
+	invalidator, ok := c.cfg.TokenProvider.(TokenInvalidator)
+	if !ok {
+		return
+	}
+
+	invalidator.Invalidate(token)
+
+	invalidated = true
+
+	return
+}
+
+// NewRefreshingTokenProvider creates a RefreshingTokenProvider.
+//
+// Parameters:
+//   - fetch: The function fetching new tokens.
+//   - margin: How long before their expiry tokens are refreshed, to absorb clock skew and latency.
+//
+// Returns:
+//   - provider: The new provider.
+func NewRefreshingTokenProvider(fetch TokenFetcher, margin time.Duration) (provider *RefreshingTokenProvider) {
+	provider = &RefreshingTokenProvider{
+		fetch:  fetch,
+		margin: margin,
+	}
+
+	return
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"io"
+	"net/http"
+)
+
+// BodyTransformer defines a function type transforming a request or response body as a
+// stream, e.g. to encrypt request bodies or decrypt responses. Transformers are chained as
+// ordered stages, each one reading from the output of the previous one.
+//
+// Parameters:
+//   - header: The header of the request or response. A request transformer may update it
+//     (e.g., Content-Type), using Set rather than Add as it runs again on every retry.
+//   - body: The body to transform.
+//
+// Returns:
+//   - transformed: The transformed body. Errors are reported through its Read method.
+type BodyTransformer func(header http.Header, body io.Reader) (transformed io.Reader)
+
+// transformedBody is a transformed body, closing the original body when closed.
+type transformedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// chainTransformers applies the transformers to body, in order.
+//
+// Parameters:
+//   - header: The header passed to the transformers.
+//   - body: The body to transform.
+//   - transformers: The stages, in order.
+//
+// Returns:
+//   - transformed: The body read through every stage.
+func chainTransformers(header http.Header, body io.ReadCloser, transformers ...[]BodyTransformer) (transformed io.ReadCloser) {
+	var reader io.Reader = body
+
+	for _, stages := range transformers {
+		for _, transformer := range stages {
+			reader = transformer(header, reader)
+		}
+	}
+
+	transformed = &transformedBody{Reader: reader, Closer: body}
+
+	return
+}
+
+// transformRequestBody applies the client and request body transformers to the body of an
+// attempt. The transformed length being unknown, the body is sent chunked.
+//
+// Parameters:
+//   - req: The request, holding its own transformers.
+//   - attempt: The request of the attempt, updated in place.
+//
+// Returns: None.
+func (c *Client) transformRequestBody(req *Request, attempt *http.Request) {
+	if attempt.Body == nil || attempt.Body == http.NoBody {
+		return
+	}
+
+	if len(c.cfg.RequestBodyTransformers) == 0 && len(req.requestBodyTransformers) == 0 {
+		return
+	}
+
+	attempt.Body = chainTransformers(attempt.Header, attempt.Body, c.cfg.RequestBodyTransformers, req.requestBodyTransformers)
+	attempt.ContentLength = -1
+	attempt.GetBody = nil
+}
+
+// transformResponseBody applies the client and request body transformers to the body of
+// the final response.
+//
+// Parameters:
+//   - req: The request, holding its own transformers.
+//   - res: The response, updated in place.
+//
+// Returns: None.
+func (c *Client) transformResponseBody(req *Request, res *http.Response) {
+	if len(c.cfg.ResponseBodyTransformers) == 0 && len(req.responseBodyTransformers) == 0 {
+		return
+	}
+
+	res.Body = chainTransformers(res.Header, res.Body, c.cfg.ResponseBodyTransformers, req.responseBodyTransformers)
+	res.ContentLength = -1
+}
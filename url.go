@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/url"
+	"strings"
+)
+
+// joinURL resolves a request URL against a client base URL using the following rules:
+//   - An empty base leaves the reference untouched.
+//   - An absolute reference (with a scheme) overrides the base entirely.
+//   - A protocol-relative reference ("//host/path") overrides the base but keeps its scheme.
+//   - Otherwise the reference path is appended to the base path with exactly one slash in
+//     between; an empty reference path keeps the base path as is.
+//   - Query strings of base and reference are concatenated, base first, without re-encoding
+//     or reordering parameters.
+//   - The reference fragment, if any, replaces the base fragment.
+//
+// Paths are joined in their escaped form so pre-encoded components (e.g., %2F) are preserved.
+//
+// Parameters:
+//   - base: The base URL. Can be empty.
+//   - ref: The request URL, relative to base or absolute.
+//
+// Returns:
+//   - joined: The resolved URL.
+//   - err: An error if either URL cannot be parsed.
+func joinURL(base, ref string) (joined string, err error) {
+	if base == "" {
+		joined = ref
+
+		return
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return
+	}
+
+	if refURL.IsAbs() {
+		joined = ref
+
+		return
+	}
+
+	if refURL.Host != "" {
+		refURL.Scheme = baseURL.Scheme
+
+		joined = refURL.String()
+
+		return
+	}
+
+	if refPath := refURL.EscapedPath(); refPath != "" {
+		escapedPath := strings.TrimSuffix(baseURL.EscapedPath(), "/") + "/" + strings.TrimPrefix(refPath, "/")
+
+		baseURL.Path, err = url.PathUnescape(escapedPath)
+		if err != nil {
+			return
+		}
+
+		baseURL.RawPath = escapedPath
+	}
+
+	switch {
+	case baseURL.RawQuery == "":
+		baseURL.RawQuery = refURL.RawQuery
+	case refURL.RawQuery != "":
+		baseURL.RawQuery += "&" + refURL.RawQuery
+	}
+
+	if strings.Contains(ref, "#") {
+		baseURL.Fragment = refURL.Fragment
+		baseURL.RawFragment = refURL.RawFragment
+	}
+
+	joined = baseURL.String()
+
+	return
+}
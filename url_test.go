@@ -0,0 +1,128 @@
+package http
+
+import "testing"
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "empty base",
+			ref:  "/path?a=1",
+			want: "/path?a=1",
+		},
+		{
+			name: "absolute reference",
+			base: "https://example.com/api?key=1",
+			ref:  "http://other.com/path?a=1#top",
+			want: "http://other.com/path?a=1#top",
+		},
+		{
+			name: "protocol-relative reference",
+			base: "https://example.com/api",
+			ref:  "//other.com/path?a=1",
+			want: "https://other.com/path?a=1",
+		},
+		{
+			name: "relative path",
+			base: "https://example.com/api",
+			ref:  "users",
+			want: "https://example.com/api/users",
+		},
+		{
+			name: "slashes on both sides",
+			base: "https://example.com/api/",
+			ref:  "/users",
+			want: "https://example.com/api/users",
+		},
+		{
+			name: "slashes on neither side",
+			base: "https://example.com",
+			ref:  "users",
+			want: "https://example.com/users",
+		},
+		{
+			name: "trailing slash of reference kept",
+			base: "https://example.com/api",
+			ref:  "/users/",
+			want: "https://example.com/api/users/",
+		},
+		{
+			name: "empty reference path",
+			base: "https://example.com/api/",
+			ref:  "?a=1",
+			want: "https://example.com/api/?a=1",
+		},
+		{
+			name: "pre-encoded segments",
+			base: "https://example.com/a%2Fb",
+			ref:  "c%2Fd/e%20f",
+			want: "https://example.com/a%2Fb/c%2Fd/e%20f",
+		},
+		{
+			name: "base query only",
+			base: "https://example.com/api?key=1",
+			ref:  "/users",
+			want: "https://example.com/api/users?key=1",
+		},
+		{
+			name: "base and reference queries",
+			base: "https://example.com/api?key=1&b=2",
+			ref:  "/users?a=1&key=3",
+			want: "https://example.com/api/users?key=1&b=2&a=1&key=3",
+		},
+		{
+			name: "pre-encoded query",
+			base: "https://example.com/api?q=a%20b",
+			ref:  "/users?r=c+d",
+			want: "https://example.com/api/users?q=a%20b&r=c+d",
+		},
+		{
+			name: "base fragment kept",
+			base: "https://example.com/api#base",
+			ref:  "/users",
+			want: "https://example.com/api/users#base",
+		},
+		{
+			name: "reference fragment replaces base fragment",
+			base: "https://example.com/api#base",
+			ref:  "/users#ref",
+			want: "https://example.com/api/users#ref",
+		},
+		{
+			name: "empty reference fragment clears base fragment",
+			base: "https://example.com/api#base",
+			ref:  "/users#",
+			want: "https://example.com/api/users",
+		},
+		{
+			name:    "invalid reference",
+			base:    "https://example.com",
+			ref:     "%zz",
+			wantErr: true,
+		},
+		{
+			name:    "invalid base",
+			base:    "https://example.com/%zz",
+			ref:     "/users",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := joinURL(tt.base, tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package http
 
 import (
+	"context"
 	"io"
+	"time"
 
 	hqgoreaderutil "github.com/hueristiq/hqgoutils/reader"
 )
@@ -10,6 +12,9 @@ type ContextOverride string
 
 const (
 	RetryMax ContextOverride = "retry-max"
+	// Timeout overrides ClientConfiguration.Timeout for a request when set to a positive
+	// time.Duration on its context (see RequestBuilder.Timeout).
+	Timeout ContextOverride = "timeout"
 )
 
 func getReusableBodyandContentLength(rawBody interface{}) (reader *hqgoreaderutil.ReusableReadCloser, length int64, err error) {
@@ -52,3 +57,19 @@ func getReusableBodyandContentLength(rawBody interface{}) (reader *hqgoreaderuti
 
 	return
 }
+
+// requestTimeout returns the timeout a request context overrides ClientConfiguration.Timeout with.
+//
+// Parameters:
+//   - ctx: The request context.
+//
+// Returns:
+//   - timeout: The timeout.
+//   - ok: Whether the context carries a positive Timeout override.
+func requestTimeout(ctx context.Context) (timeout time.Duration, ok bool) {
+	timeout, ok = ctx.Value(Timeout).(time.Duration)
+
+	ok = ok && timeout > 0
+
+	return
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.source.hueristiq.com/http/headers"
+)
+
+// CacheKey computes a normalized key identifying the representation a response was selected for.
+// The key is built from the request method and normalized URL (lowercased scheme and host,
+// default port removed, query parameters sorted, fragment dropped) plus the values of every
+// request header listed in the response's Vary header. Two requests yielding the same key
+// can be expected to receive the same response, which makes the key usable both for caching
+// and for comparing responses.
+//
+// Parameters:
+//   - req: The request the response was obtained for.
+//   - res: The response whose Vary header selects the request headers to include. Can be nil,
+//     in which case only the method and URL are used.
+//
+// Returns:
+//   - key: The normalized key.
+//   - ok: False if the response carries "Vary: *", meaning it cannot be matched by any key.
+func CacheKey(req *http.Request, res *http.Response) (key string, ok bool) {
+	var builder strings.Builder
+
+	builder.WriteString(strings.ToUpper(req.Method))
+	builder.WriteByte(' ')
+	builder.WriteString(normalizeCacheURL(req.URL))
+
+	if res == nil {
+		key, ok = builder.String(), true
+
+		return
+	}
+
+	fields := VaryFields(res.Header)
+
+	for _, field := range fields {
+		if field == "*" {
+			return
+		}
+
+		builder.WriteByte('\n')
+		builder.WriteString(strings.ToLower(field))
+		builder.WriteByte(':')
+		builder.WriteString(strings.Join(req.Header.Values(field), ","))
+	}
+
+	key, ok = builder.String(), true
+
+	return
+}
+
+// VaryFields returns the canonicalized, de-duplicated and sorted request header names listed
+// in the Vary header(s) of the given response header set.
+//
+// Parameters:
+//   - header: The response header.
+//
+// Returns:
+//   - fields: The header names the response varies on. Contains "*" if the response varies on
+//     everything.
+func VaryFields(header http.Header) (fields []string) {
+	seen := make(map[string]struct{})
+
+	for _, value := range header.Values(headers.Vary.String()) {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+
+			if field == "" {
+				continue
+			}
+
+			if field != "*" {
+				field = http.CanonicalHeaderKey(field)
+			}
+
+			if _, exists := seen[field]; exists {
+				continue
+			}
+
+			seen[field] = struct{}{}
+
+			fields = append(fields, field)
+		}
+	}
+
+	sort.Strings(fields)
+
+	return
+}
+
+// normalizeCacheURL renders a URL in the normalized form used by CacheKey.
+//
+// Parameters:
+//   - u: The URL to normalize.
+//
+// Returns:
+//   - normalized: The normalized URL string.
+func normalizeCacheURL(u *url.URL) (normalized string) {
+	clone := *u
+
+	clone.Scheme = strings.ToLower(clone.Scheme)
+	clone.Host = strings.ToLower(clone.Host)
+	clone.Fragment = ""
+	clone.RawFragment = ""
+
+	if host, port, err := net.SplitHostPort(clone.Host); err == nil {
+		if (clone.Scheme == "http" && port == "80") || (clone.Scheme == "https" && port == "443") {
+			clone.Host = host
+
+			if strings.Contains(host, ":") {
+				clone.Host = "[" + host + "]"
+			}
+		}
+	}
+
+	if clone.Path == "" {
+		clone.Path = "/"
+		clone.RawPath = ""
+	}
+
+	clone.RawQuery = clone.Query().Encode()
+
+	normalized = clone.String()
+
+	return
+}